@@ -0,0 +1,89 @@
+package panaccess
+
+import (
+	"errors"
+	"net/url"
+	"time"
+)
+
+//InactivityReport smartcards not seen since a date grouped by subscriber and region
+type InactivityReport struct {
+	Since        time.Time
+	Smartcards   []Smartcard
+	BySubscriber map[string][]Smartcard
+	ByRegion     map[int][]Smartcard
+}
+
+//LastSeen latest activation or service list download of smartcard, zero if never seen
+func (card *Smartcard) LastSeen() time.Time {
+	activation := parseTime(card.LastActivation)
+	download := parseTime(card.LastServiceListDownload)
+	if download.After(activation) {
+		return download
+	}
+	return activation
+}
+
+//GetInactive smartcards assigned to a subscriber which haven't been seen for days.
+//Defective, blacklisted and disabled cards are not expected to be seen and are left out.
+func (card *Smartcard) GetInactive(pan *Panaccess, days int) ([]Smartcard, error) {
+	since, err := inactivitySince(days)
+	if err != nil {
+		return nil, err
+	}
+	return card.getInactiveSince(pan, since)
+}
+
+//GetInactivityReport of smartcards not seen for days
+func (card *Smartcard) GetInactivityReport(pan *Panaccess, days int) (*InactivityReport, error) {
+	since, err := inactivitySince(days)
+	if err != nil {
+		return nil, err
+	}
+	inactive, err := card.getInactiveSince(pan, since)
+	if err != nil {
+		return nil, err
+	}
+	report := &InactivityReport{
+		Since:        since,
+		Smartcards:   inactive,
+		BySubscriber: map[string][]Smartcard{},
+		ByRegion:     map[int][]Smartcard{},
+	}
+	for _, c := range inactive {
+		report.BySubscriber[c.SubscriberCode] = append(report.BySubscriber[c.SubscriberCode], c)
+		report.ByRegion[c.RegionID] = append(report.ByRegion[c.RegionID], c)
+	}
+	return report, nil
+}
+
+//inactivitySince cutoff for days of inactivity
+func inactivitySince(days int) (time.Time, error) {
+	if days <= 0 {
+		return time.Time{}, errors.New("Inactivity days must be positive")
+	}
+	return time.Now().AddDate(0, 0, -days), nil
+}
+
+//getInactiveSince every smartcard expected to be alive and not seen since
+func (card *Smartcard) getInactiveSince(pan *Panaccess, since time.Time) ([]Smartcard, error) {
+	cards, err := card.GetAll(pan, &url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	return filterInactive(cards, since), nil
+}
+
+//filterInactive cards assigned to a subscriber, usable and not seen since
+func filterInactive(cards []Smartcard, since time.Time) []Smartcard {
+	inactive := []Smartcard{}
+	for _, c := range cards {
+		if c.SubscriberCode == "" || c.Defect || c.Blacklisted || c.Disabled {
+			continue
+		}
+		if c.LastSeen().Before(since) {
+			inactive = append(inactive, c)
+		}
+	}
+	return inactive
+}
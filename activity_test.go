@@ -0,0 +1,33 @@
+package panaccess
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterInactive(t *testing.T) {
+	old := time.Now().AddDate(0, 0, -40).Format(timeLayout)
+	recent := time.Now().AddDate(0, 0, -1).Format(timeLayout)
+	cards := []Smartcard{
+		{SN: "dead", SubscriberCode: "A", LastActivation: old},
+		{SN: "never", SubscriberCode: "A"},
+		{SN: "alive", SubscriberCode: "A", LastActivation: old, LastServiceListDownload: recent},
+		{SN: "unassigned", LastActivation: old},
+		{SN: "defect", SubscriberCode: "A", LastActivation: old, Defect: true},
+		{SN: "blacklisted", SubscriberCode: "A", LastActivation: old, Blacklisted: true},
+		{SN: "disabled", SubscriberCode: "A", LastActivation: old, Disabled: true},
+	}
+	got := filterInactive(cards, time.Now().AddDate(0, 0, -30))
+	if len(got) != 2 || got[0].SN != "dead" || got[1].SN != "never" {
+		t.Errorf("filterInactive = %v, want dead and never", got)
+	}
+}
+
+func TestInactivityDaysMustBePositive(t *testing.T) {
+	card := Smartcard{}
+	for _, days := range []int{0, -1} {
+		if _, err := card.GetInactivityReport(&Panaccess{}, days); err == nil {
+			t.Errorf("GetInactivityReport(%d) didn't fail", days)
+		}
+	}
+}
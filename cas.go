@@ -0,0 +1,67 @@
+package panaccess
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+//CasID identifier of a conditional access system
+type CasID uint16
+
+func (id CasID) String() string {
+	return fmt.Sprintf("0x%04X", uint16(id))
+}
+
+//ParseCasIDs of smartcard. IDs may be separated by commas, semicolons or spaces
+//and are always hex, with or without "0x" prefix.
+func (card *Smartcard) ParseCasIDs() ([]CasID, error) {
+	ids := []CasID{}
+	fields := strings.FieldsFunc(card.CasIDs, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t'
+	})
+	for _, field := range fields {
+		hex := strings.TrimPrefix(strings.TrimPrefix(field, "0x"), "0X")
+		id, err := strconv.ParseUint(hex, 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CAS ID %q of smartcard %s", field, card.SN)
+		}
+		ids = append(ids, CasID(id))
+	}
+	return ids, nil
+}
+
+//HasCasID reports if smartcard is provisioned for the CAS id, unparseable lists never match
+func (card *Smartcard) HasCasID(id CasID) bool {
+	ids, err := card.ParseCasIDs()
+	if err != nil {
+		return false
+	}
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+//FilterByCasID smartcards provisioned for the CAS id
+func (cards Smartcards) FilterByCasID(id CasID) Smartcards {
+	ret := Smartcards{}
+	for i := range cards {
+		if cards[i].HasCasID(id) {
+			ret = append(ret, cards[i])
+		}
+	}
+	return ret
+}
+
+//GetByCasID every smartcard of panaccess provisioned for the CAS id
+func (card *Smartcard) GetByCasID(pan *Panaccess, id CasID) (Smartcards, error) {
+	cards, err := card.GetAll(pan, &url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	return Smartcards(cards).FilterByCasID(id), nil
+}
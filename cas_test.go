@@ -0,0 +1,47 @@
+package panaccess
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCasIDs(t *testing.T) {
+	tests := []struct {
+		casIDs string
+		want   []CasID
+		err    bool
+	}{
+		{"", []CasID{}, false},
+		{"0B00", []CasID{0x0B00}, false},
+		{"0500", []CasID{0x0500}, false},
+		{"1801", []CasID{0x1801}, false},
+		{"0x0B00", []CasID{0x0B00}, false},
+		{"0X0b00", []CasID{0x0B00}, false},
+		{"0B00, 0500;1801 0x4AE1", []CasID{0x0B00, 0x0500, 0x1801, 0x4AE1}, false},
+		{"0B00,ZZ", nil, true},
+		{"10000", nil, true},
+	}
+	for _, test := range tests {
+		card := Smartcard{SN: "1", CasIDs: test.casIDs}
+		got, err := card.ParseCasIDs()
+		if (err != nil) != test.err {
+			t.Errorf("ParseCasIDs(%q) error = %v, want error %v", test.casIDs, err, test.err)
+			continue
+		}
+		if !test.err && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseCasIDs(%q) = %v, want %v", test.casIDs, got, test.want)
+		}
+	}
+}
+
+func TestFilterByCasID(t *testing.T) {
+	cards := Smartcards{
+		{SN: "1", CasIDs: "0B00,0500"},
+		{SN: "2", CasIDs: "1801"},
+		{SN: "3", CasIDs: "invalid"},
+	}
+	got := cards.FilterByCasID(0x0500)
+	if len(got) != 1 || got[0].SN != "1" {
+		t.Errorf("FilterByCasID(0x0500) = %v, want card 1", got)
+	}
+}
@@ -0,0 +1,157 @@
+package panaccess
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+//ProductCatalog local cache of panaccess products indexed by ID and name.
+//Deleted products are not indexed. The zero value is ready to use.
+type ProductCatalog struct {
+	mu       sync.RWMutex
+	byID     map[int]Product
+	byName   map[string]Product
+	loadedAt time.Time
+	stop     chan struct{}
+}
+
+//Load all products from panaccess replacing the cached ones
+func (cat *ProductCatalog) Load(pan *Panaccess) error {
+	prod := Product{}
+	prods, err := prod.GetAll(pan, &url.Values{})
+	if err != nil {
+		return err
+	}
+	byID := map[int]Product{}
+	byName := map[string]Product{}
+	for _, p := range prods {
+		if p.Deleted {
+			continue
+		}
+		byID[p.ID] = p
+		byName[p.Name] = p
+	}
+	cat.mu.Lock()
+	cat.byID = byID
+	cat.byName = byName
+	cat.loadedAt = time.Now()
+	cat.mu.Unlock()
+	return nil
+}
+
+//update a single product of the catalog, its previous entry is evicted
+//and deleted products are not indexed again
+func (cat *ProductCatalog) update(prod Product) {
+	cat.mu.Lock()
+	defer cat.mu.Unlock()
+	if cat.byID == nil {
+		cat.byID = map[int]Product{}
+		cat.byName = map[string]Product{}
+	}
+	if old, ok := cat.byID[prod.ID]; ok {
+		delete(cat.byID, old.ID)
+		if cat.byName[old.Name].ID == old.ID {
+			delete(cat.byName, old.Name)
+		}
+	}
+	if prod.Deleted {
+		return
+	}
+	cat.byID[prod.ID] = prod
+	cat.byName[prod.Name] = prod
+}
+
+//Loaded reports if the catalog has been loaded at least once
+func (cat *ProductCatalog) Loaded() bool {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	return !cat.loadedAt.IsZero()
+}
+
+//LoadedAt time of the last successful Load
+func (cat *ProductCatalog) LoadedAt() time.Time {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	return cat.loadedAt
+}
+
+//ByID product from catalog
+func (cat *ProductCatalog) ByID(id int) (*Product, error) {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	prod, ok := cat.byID[id]
+	if !ok {
+		return nil, &NotFoundError{Entity: "Product", Key: fmt.Sprint(id)}
+	}
+	return &prod, nil
+}
+
+//ByName product from catalog
+func (cat *ProductCatalog) ByName(name string) (*Product, error) {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	prod, ok := cat.byName[name]
+	if !ok {
+		return nil, &NotFoundError{Entity: "Product", Key: name}
+	}
+	return &prod, nil
+}
+
+//Products in catalog
+func (cat *ProductCatalog) Products() []Product {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	prods := make([]Product, 0, len(cat.byID))
+	for _, p := range cat.byID {
+		prods = append(prods, p)
+	}
+	return prods
+}
+
+//StartRefresh reloads the catalog every interval until StopRefresh is called.
+//Failed reloads keep the previous products and are passed to onError if not nil.
+//Panaccess isn't safe for concurrent use (Call may login again and change the session),
+//so pan must be a separate client used only by the refresh, e.g. a new Panaccess
+//with the same credentials. The client the catalog is attached to is rejected.
+func (cat *ProductCatalog) StartRefresh(pan *Panaccess, interval time.Duration, onError func(error)) error {
+	if interval <= 0 {
+		return errors.New("Refresh interval must be positive")
+	}
+	if pan.Products == cat {
+		return errors.New("Refresh needs its own Panaccess client")
+	}
+	cat.StopRefresh()
+	stop := make(chan struct{})
+	cat.mu.Lock()
+	cat.stop = stop
+	cat.mu.Unlock()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := cat.Load(pan)
+				if err != nil && onError != nil {
+					onError(err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+//StopRefresh stops the scheduled reloads
+func (cat *ProductCatalog) StopRefresh() {
+	cat.mu.Lock()
+	defer cat.mu.Unlock()
+	if cat.stop != nil {
+		close(cat.stop)
+		cat.stop = nil
+	}
+}
@@ -0,0 +1,70 @@
+package panaccess
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProductCatalogFollowsProductChanges(t *testing.T) {
+	_, pan := newMockServer(t)
+	pan.Products = &ProductCatalog{}
+	pan.Products.update(Product{ID: 1, Name: "Basic"})
+	prod := Product{ID: 1, Name: "Basic"}
+
+	err := prod.Rename(pan, "Premium")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = pan.Products.ByName("Basic"); err == nil {
+		t.Error("Old name still resolvable after Rename")
+	}
+	if got, err := pan.Products.ByID(1); err != nil || got.Name != "Premium" {
+		t.Errorf("ByID(1) after Rename = %v, %v", got, err)
+	}
+
+	err = prod.Delete(pan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = pan.Products.ByID(1); err == nil {
+		t.Error("Deleted product still resolvable by ID")
+	}
+	if _, err = pan.Products.ByName("Premium"); err == nil {
+		t.Error("Deleted product still resolvable by name")
+	}
+
+	err = prod.Undelete(pan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = pan.Products.ByName("Premium"); err != nil {
+		t.Errorf("Undeleted product not resolvable: %v", err)
+	}
+
+	created := Product{Name: "Sports"}
+	err = created.Create(pan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := pan.Products.ByName("Sports"); err != nil || got.ID != 42 {
+		t.Errorf("ByName(Sports) after Create = %v, %v", got, err)
+	}
+}
+
+func TestProductCatalogRefreshInterval(t *testing.T) {
+	cat := ProductCatalog{}
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if err := cat.StartRefresh(&Panaccess{}, interval, nil); err == nil {
+			t.Errorf("StartRefresh(%v) didn't fail", interval)
+		}
+	}
+}
+
+func TestProductCatalogRefreshNeedsOwnClient(t *testing.T) {
+	cat := &ProductCatalog{}
+	pan := &Panaccess{Products: cat}
+	if err := cat.StartRefresh(pan, time.Minute, nil); err == nil {
+		cat.StopRefresh()
+		t.Error("StartRefresh accepted the client the catalog is attached to")
+	}
+}
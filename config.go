@@ -0,0 +1,65 @@
+package panaccess
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+)
+
+//ErrConfigProtected returned when changing the config of a protected smartcard without override
+var ErrConfigProtected = errors.New("Smartcard config is protected")
+
+//GetListOfSmartcardConfigsResponse from panaccess
+type GetListOfSmartcardConfigsResponse struct {
+	Count         int               `json:"count"`
+	ConfigEntries []SmartcardConfig `json:"configEntries"`
+}
+
+//SmartcardConfig class representation from panaccess
+type SmartcardConfig struct {
+	ID          string `json:"configId"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+//Get smartcard configs from panaccess
+func (cfg *SmartcardConfig) Get(pan *Panaccess, params *url.Values) ([]SmartcardConfig, error) {
+	//Everything has a limit
+	if (*params).Get("limit") == "" {
+		(*params).Add("limit", "1000")
+	}
+	//Call Function
+	resp, err := pan.Call(
+		"getListOfSmartcardConfigs",
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	//Retrieve all rows and parse as a slice of SmartcardConfig
+	var rows GetListOfSmartcardConfigsResponse
+	bodyBytes, err := json.Marshal(resp.Answer)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bodyBytes, &rows)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+	return rows.ConfigEntries, nil
+}
+
+//AssignTo sets config on every card, protected cards are skipped unless override
+func (cfg *SmartcardConfig) AssignTo(pan *Panaccess, cards []Smartcard, override bool) []CommandResult {
+	results := []CommandResult{}
+	for i := range cards {
+		results = append(results, CommandResult{
+			SN:    cards[i].SN,
+			Error: cards[i].SetConfig(pan, cfg.ID, override),
+		})
+	}
+	return results
+}
@@ -0,0 +1,84 @@
+package panaccess
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+//Fingerprint shown on screen to identify the smartcard of a pirated stream
+type Fingerprint struct {
+	//JobID returned by panaccess once triggered
+	JobID int
+	//X and Y position on screen in percent, negative values let the box choose
+	X int
+	Y int
+	//Duration on screen in seconds
+	Duration int
+}
+
+//validate fingerprint options
+func (fp *Fingerprint) validate() error {
+	if fp.X > 100 || fp.Y > 100 {
+		return errors.New("Fingerprint position must be a percent")
+	}
+	if (fp.X < 0) != (fp.Y < 0) {
+		return errors.New("Fingerprint position needs both X and Y, or none")
+	}
+	if fp.Duration <= 0 {
+		return errors.New("Fingerprint duration must be positive")
+	}
+	return nil
+}
+
+//trigger fingerprint with the common params plus the target ones
+func (fp *Fingerprint) trigger(pan *Panaccess, params url.Values) (int, error) {
+	err := fp.validate()
+	if err != nil {
+		return 0, err
+	}
+	if fp.X >= 0 && fp.Y >= 0 {
+		params.Add("x", fmt.Sprint(fp.X))
+		params.Add("y", fmt.Sprint(fp.Y))
+	}
+	params.Add("duration", fmt.Sprint(fp.Duration))
+	//Call Function
+	resp, err := pan.Call(
+		"triggerFingerprint",
+		&params,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if !resp.Success {
+		return 0, errors.New(resp.ErrorMessage)
+	}
+	//Answer is the jobId
+	id, ok := resp.Answer.(float64)
+	if !ok {
+		return 0, errors.New("Unexpected answer from triggerFingerprint")
+	}
+	fp.JobID = int(id)
+	return fp.JobID, nil
+}
+
+//TriggerOnSmartcard fingerprint at panaccess
+func (fp *Fingerprint) TriggerOnSmartcard(pan *Panaccess, card *Smartcard) (int, error) {
+	params := url.Values{}
+	params.Add("smartcardId", card.SN)
+	return fp.trigger(pan, params)
+}
+
+//TriggerOnSubscriber fingerprint on all smartcards of subscriber at panaccess
+func (fp *Fingerprint) TriggerOnSubscriber(pan *Panaccess, sub *Subscriber) (int, error) {
+	params := url.Values{}
+	params.Add("subscriberCode", sub.SubscriberCode)
+	return fp.trigger(pan, params)
+}
+
+//TriggerOnRegion fingerprint on all smartcards of region at panaccess
+func (fp *Fingerprint) TriggerOnRegion(pan *Panaccess, region *Region) (int, error) {
+	params := url.Values{}
+	params.Add("regionId", fmt.Sprint(region.ID))
+	return fp.trigger(pan, params)
+}
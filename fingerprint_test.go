@@ -0,0 +1,25 @@
+package panaccess
+
+import "testing"
+
+func TestFingerprintValidate(t *testing.T) {
+	tests := []struct {
+		fp Fingerprint
+		ok bool
+	}{
+		{Fingerprint{X: 10, Y: 20, Duration: 5}, true},
+		{Fingerprint{X: -1, Y: -1, Duration: 5}, true},
+		{Fingerprint{X: 0, Y: 100, Duration: 1}, true},
+		{Fingerprint{X: 10, Y: -1, Duration: 5}, false},
+		{Fingerprint{X: -1, Y: 10, Duration: 5}, false},
+		{Fingerprint{X: 101, Y: 10, Duration: 5}, false},
+		{Fingerprint{X: 10, Y: 10}, false},
+		{Fingerprint{X: 10, Y: 10, Duration: -5}, false},
+	}
+	for _, test := range tests {
+		err := test.fp.validate()
+		if (err == nil) != test.ok {
+			t.Errorf("validate(%+v) error = %v, want ok %v", test.fp, err, test.ok)
+		}
+	}
+}
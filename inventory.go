@@ -0,0 +1,146 @@
+package panaccess
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//Inventory of smartcards grouped by versions and set-top box models
+type Inventory struct {
+	Total    int            `json:"total"`
+	Firmware map[string]int `json:"firmware"`
+	Camlib   map[string]int `json:"camlib"`
+	//STBModel key is "vendor/model/chipset"
+	STBModel   map[string]int `json:"stbModel"`
+	Smartcards []Smartcard    `json:"-"`
+}
+
+//Load inventory with every smartcard of panaccess
+func (inv *Inventory) Load(pan *Panaccess) error {
+	card := Smartcard{}
+	cards, err := card.GetAll(pan, &url.Values{})
+	if err != nil {
+		return err
+	}
+	inv.Build(cards)
+	return nil
+}
+
+//Build inventory from cards
+func (inv *Inventory) Build(cards []Smartcard) {
+	inv.Total = len(cards)
+	inv.Firmware = map[string]int{}
+	inv.Camlib = map[string]int{}
+	inv.STBModel = map[string]int{}
+	inv.Smartcards = cards
+	for _, card := range cards {
+		inv.Firmware[card.FirmwareVersion]++
+		inv.Camlib[card.CamlibVersion]++
+		inv.STBModel[card.STBVendor+"/"+card.STBModel+"/"+card.STBChipset]++
+	}
+}
+
+//BelowVersion smartcards with firmware lower than minFirmware or camlib lower than minCamlib.
+//Empty minimums are not checked.
+func (inv *Inventory) BelowVersion(minFirmware, minCamlib string) []Smartcard {
+	ret := []Smartcard{}
+	for _, card := range inv.Smartcards {
+		if (minFirmware != "" && compareVersions(card.FirmwareVersion, minFirmware) < 0) ||
+			(minCamlib != "" && compareVersions(card.CamlibVersion, minCamlib) < 0) {
+			ret = append(ret, card)
+		}
+	}
+	return ret
+}
+
+//WriteJSON the inventory distributions
+func (inv *Inventory) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(inv)
+}
+
+//WriteCSV the inventory distributions as category,value,count rows
+func (inv *Inventory) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	err := out.Write([]string{"category", "value", "count"})
+	if err != nil {
+		return err
+	}
+	for _, group := range []struct {
+		name   string
+		values map[string]int
+	}{
+		{"firmware", inv.Firmware},
+		{"camlib", inv.Camlib},
+		{"stbModel", inv.STBModel},
+	} {
+		keys := make([]string, 0, len(group.values))
+		for k := range group.values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			err = out.Write([]string{group.name, k, fmt.Sprint(group.values[k])})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+//WriteSmartcardsCSV writes cards versions and models, useful with BelowVersion
+func WriteSmartcardsCSV(w io.Writer, cards []Smartcard) error {
+	out := csv.NewWriter(w)
+	err := out.Write([]string{"sn", "subscriberCode", "firmwareVersion", "camlibVersion", "stbVendor", "stbModel", "stbChipset"})
+	if err != nil {
+		return err
+	}
+	for _, card := range cards {
+		err = out.Write([]string{card.SN, card.SubscriberCode, card.FirmwareVersion, card.CamlibVersion, card.STBVendor, card.STBModel, card.STBChipset})
+		if err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+//compareVersions dotted versions numerically, returns -1, 0 or 1
+func compareVersions(a, b string) int {
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var sa, sb string
+		if i < len(pa) {
+			sa = pa[i]
+		}
+		if i < len(pb) {
+			sb = pb[i]
+		}
+		na, errA := strconv.Atoi(sa)
+		nb, errB := strconv.Atoi(sb)
+		if sa == "" {
+			na, errA = 0, nil
+		}
+		if sb == "" {
+			nb, errB = 0, nil
+		}
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			if na < nb {
+				return -1
+			}
+			return 1
+		case (errA != nil || errB != nil) && sa != sb:
+			return strings.Compare(sa, sb)
+		}
+	}
+	return 0
+}
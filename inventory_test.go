@@ -0,0 +1,42 @@
+package panaccess
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"1.2", "1.10", -1},
+		{"1.10", "1.2", 1},
+		{"2", "1.9.9", 1},
+		{"", "1.0", -1},
+		{"1.0", "", 1},
+		{"1.0.beta", "1.0.beta", 0},
+		{"1.0.alpha", "1.0.beta", -1},
+		{"1.0.beta", "1.0.alpha", 1},
+	}
+	for _, test := range tests {
+		if got := compareVersions(test.a, test.b); got != test.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestBelowVersion(t *testing.T) {
+	inv := Inventory{}
+	inv.Build([]Smartcard{
+		{SN: "1", FirmwareVersion: "1.2", CamlibVersion: "3.0"},
+		{SN: "2", FirmwareVersion: "1.10", CamlibVersion: "2.9"},
+		{SN: "3", FirmwareVersion: "1.10", CamlibVersion: "3.1"},
+	})
+	got := inv.BelowVersion("1.10", "3.0")
+	if len(got) != 2 || got[0].SN != "1" || got[1].SN != "2" {
+		t.Errorf("BelowVersion = %v, want cards 1 and 2", got)
+	}
+	if inv.Firmware["1.10"] != 2 {
+		t.Errorf("Firmware[1.10] = %d, want 2", inv.Firmware["1.10"])
+	}
+}
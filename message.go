@@ -0,0 +1,156 @@
+package panaccess
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+//GetListOfMessagesResponse from panaccess
+type GetListOfMessagesResponse struct {
+	Count          int       `json:"count"`
+	MessageEntries []Message `json:"messageEntries"`
+}
+
+//Message class representation from panaccess, shown on screen (OSD) by the boxes
+type Message struct {
+	ID   int    `json:"messageId"`
+	Text string `json:"text"`
+	//Priority higher values are shown first
+	Priority int `json:"priority"`
+	//Duration on screen in seconds, 0 lets the box decide
+	Duration int `json:"duration"`
+	//Forced the subscriber can't close the message
+	Forced     bool     `json:"forced"`
+	Smartcards []string `json:"smartcards"`
+	RegionID   int      `json:"regionId"`
+	Created    string   `json:"created"`
+}
+
+//Get sent messages from panaccess
+func (msg *Message) Get(pan *Panaccess, params *url.Values) ([]Message, error) {
+	//Everything has a limit
+	if (*params).Get("limit") == "" {
+		(*params).Add("limit", "1000")
+	}
+	//Call Function
+	resp, err := pan.Call(
+		"getListOfMessages",
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	//Retrieve all rows and parse as a slice of Message
+	var rows GetListOfMessagesResponse
+	bodyBytes, err := json.Marshal(resp.Answer)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bodyBytes, &rows)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+	return rows.MessageEntries, nil
+}
+
+//send message with the common params plus the target ones
+func (msg *Message) send(pan *Panaccess, params url.Values) error {
+	//Verify Fields
+	if msg.Text == "" {
+		return errors.New("Please fill all required fields")
+	}
+	params.Add("text", msg.Text)
+	params.Add("priority", fmt.Sprint(msg.Priority))
+	params.Add("duration", fmt.Sprint(msg.Duration))
+	params.Add("forced", fmt.Sprint(msg.Forced))
+	//Call Function
+	resp, err := pan.Call(
+		"sendOsdMessage",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	//Answer is the new messageId
+	if id, ok := resp.Answer.(float64); ok {
+		msg.ID = int(id)
+	}
+	return nil
+}
+
+//SendToSmartcards message at panaccess
+func (msg *Message) SendToSmartcards(pan *Panaccess, sns []string) error {
+	if len(sns) == 0 {
+		return errors.New("Please specify the smartcards")
+	}
+	params := url.Values{}
+	for _, sn := range sns {
+		params.Add("smartcards[]", sn)
+	}
+	err := msg.send(pan, params)
+	if err != nil {
+		return err
+	}
+	msg.Smartcards = sns
+	return nil
+}
+
+//SendToSmartcard message at panaccess
+func (msg *Message) SendToSmartcard(pan *Panaccess, card *Smartcard) error {
+	return msg.SendToSmartcards(pan, []string{card.SN})
+}
+
+//SendToSubscriber message to all smartcards of subscriber at panaccess,
+//a NotFoundError is returned if subscriber has no smartcards
+func (msg *Message) SendToSubscriber(pan *Panaccess, sub *Subscriber) error {
+	cards, err := sub.GetSmartcards(pan)
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		return &NotFoundError{Entity: "Smartcards of subscriber", Key: sub.SubscriberCode}
+	}
+	sns := []string{}
+	for _, card := range cards {
+		sns = append(sns, card.SN)
+	}
+	return msg.SendToSmartcards(pan, sns)
+}
+
+//SendToRegion message to all smartcards of region at panaccess
+func (msg *Message) SendToRegion(pan *Panaccess, region *Region) error {
+	params := url.Values{}
+	params.Add("regionId", fmt.Sprint(region.ID))
+	err := msg.send(pan, params)
+	if err != nil {
+		return err
+	}
+	msg.RegionID = region.ID
+	return nil
+}
+
+//SendWithFilter message to every smartcard matching filters at panaccess,
+//a NotFoundError is returned if no smartcard matches
+func (msg *Message) SendWithFilter(pan *Panaccess, groupOp string, filters []Rule) error {
+	card := Smartcard{}
+	cards, err := card.GetAllWithFilter(pan, &url.Values{}, groupOp, filters)
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		return &NotFoundError{Entity: "Smartcards", Key: "matching filter"}
+	}
+	sns := []string{}
+	for _, c := range cards {
+		sns = append(sns, c.SN)
+	}
+	return msg.SendToSmartcards(pan, sns)
+}
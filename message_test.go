@@ -0,0 +1,38 @@
+package panaccess
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSendWithFilterAllPages(t *testing.T) {
+	cards := []Smartcard{}
+	for i := 0; i < 1005; i++ {
+		cards = append(cards, Smartcard{SN: fmt.Sprintf("%05d", i), RegionID: 1})
+	}
+	cards = append(cards, Smartcard{SN: "99999", RegionID: 2})
+	mock, pan := newMockServer(t, cards...)
+	msg := Message{Text: "Hello"}
+	err := msg.SendWithFilter(pan, "AND", []Rule{{Field: "regionId", OP: "eq", Data: "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mock.messages) != 1 {
+		t.Fatalf("message sent %d times, want 1", len(mock.messages))
+	}
+	if len(mock.messages[0]) != 1005 {
+		t.Errorf("message sent to %d smartcards, want 1005", len(mock.messages[0]))
+	}
+}
+
+func TestSendToSubscriberWithoutSmartcards(t *testing.T) {
+	mock, pan := newMockServer(t, Smartcard{SN: "1", SubscriberCode: "OTHER"})
+	msg := Message{Text: "Hello"}
+	err := msg.SendToSubscriber(pan, &Subscriber{SubscriberCode: "SUB"})
+	if _, ok := err.(*NotFoundError); !ok {
+		t.Errorf("SendToSubscriber error = %v, want NotFoundError", err)
+	}
+	if len(mock.messages) != 0 {
+		t.Errorf("message sent %d times, want 0", len(mock.messages))
+	}
+}
@@ -0,0 +1,151 @@
+package panaccess
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+//mockServer fake panaccess keeping smartcards state in memory
+type mockServer struct {
+	mu         sync.Mutex
+	smartcards map[string]*Smartcard
+	calls      []string
+	//messages smartcards[] of every sendOsdMessage call
+	messages [][]string
+}
+
+//smartcardActions updating a smartcard flag, indexed by panaccess function
+var smartcardActions = map[string]func(card *Smartcard){
+	"blacklistSmartcard":      func(card *Smartcard) { card.Blacklisted = true },
+	"unblacklistSmartcard":    func(card *Smartcard) { card.Blacklisted = false },
+	"markSmartcardAsDefect":   func(card *Smartcard) { card.Defect = true },
+	"unmarkSmartcardAsDefect": func(card *Smartcard) { card.Defect = false },
+}
+
+//newMockServer starts a fake panaccess with cards, closed at the end of the test
+func newMockServer(t *testing.T, cards ...Smartcard) (*mockServer, *Panaccess) {
+	mock := &mockServer{smartcards: map[string]*Smartcard{}}
+	for i := range cards {
+		mock.smartcards[cards[i].SN] = &cards[i]
+	}
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+	pan := &Panaccess{
+		Servers:  []string{server.URL},
+		User:     "demo",
+		Password: "demo",
+		Token:    "token",
+		HTTP:     server.Client(),
+	}
+	err := pan.Login()
+	if err != nil {
+		t.Fatalf("Login to mock server: %v", err)
+	}
+	return mock, pan
+}
+
+func (mock *mockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	funcName := r.URL.Query().Get("f")
+	mock.calls = append(mock.calls, funcName)
+	resp := APIResponse{Success: true}
+	switch funcName {
+	case "login":
+		resp.Answer = "session"
+	case "loggedIn":
+		resp.Answer = true
+	case "getListOfSmartcards":
+		resp.Answer = mock.listSmartcards(r)
+	case "setMasterOfSmartcard":
+		card, ok := mock.smartcards[r.PostFormValue("smartcardId")]
+		if !ok {
+			resp = APIResponse{ErrorCode: "unknown_smartcard", ErrorMessage: "Smartcard not found"}
+			break
+		}
+		card.MasterSN = r.PostFormValue("masterSn")
+	case "sendOsdMessage":
+		r.ParseForm()
+		mock.messages = append(mock.messages, r.PostForm["smartcards[]"])
+		resp.Answer = len(mock.messages)
+	case "addProduct":
+		resp.Answer = 42
+	case "renameProduct", "setPackagesOfProduct", "deleteProduct", "undeleteProduct":
+	default:
+		action, ok := smartcardActions[funcName]
+		if !ok {
+			resp = APIResponse{ErrorCode: "unknown_function", ErrorMessage: funcName}
+			break
+		}
+		card, ok := mock.smartcards[r.PostFormValue("smartcardId")]
+		if !ok {
+			resp = APIResponse{ErrorCode: "unknown_smartcard", ErrorMessage: "Smartcard not found"}
+			break
+		}
+		action(card)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+//smartcard state on the mock server
+func (mock *mockServer) smartcard(sn string) Smartcard {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	return *mock.smartcards[sn]
+}
+
+//smartcardField value used by the mock filters
+func smartcardField(card *Smartcard, field string) string {
+	switch field {
+	case "sn":
+		return card.SN
+	case "subscriberCode":
+		return card.SubscriberCode
+	case "masterSn":
+		return card.MasterSN
+	case "regionId":
+		return fmt.Sprint(card.RegionID)
+	}
+	return ""
+}
+
+//listSmartcards sorted by SN applying AND filters (eq and cn), offset and limit
+func (mock *mockServer) listSmartcards(r *http.Request) GetListOfSmartcardsResponse {
+	filters := Filters{}
+	if f := r.PostFormValue("filters"); f != "" {
+		json.Unmarshal([]byte(f), &filters)
+	}
+	sns := []string{}
+	for sn := range mock.smartcards {
+		sns = append(sns, sn)
+	}
+	sort.Strings(sns)
+	matching := []Smartcard{}
+	for _, sn := range sns {
+		card := mock.smartcards[sn]
+		match := true
+		for _, rule := range filters.Rules {
+			value := smartcardField(card, rule.Field)
+			if (rule.OP == "eq" && value != rule.Data) || (rule.OP == "cn" && !strings.Contains(value, rule.Data)) {
+				match = false
+			}
+		}
+		if match {
+			matching = append(matching, *card)
+		}
+	}
+	offset, _ := strconv.Atoi(r.PostFormValue("offset"))
+	limit, _ := strconv.Atoi(r.PostFormValue("limit"))
+	page := []Smartcard{}
+	for i := offset; i < len(matching) && (limit == 0 || i < offset+limit); i++ {
+		page = append(page, matching[i])
+	}
+	return GetListOfSmartcardsResponse{Count: len(matching), SmartcardEntries: page}
+}
@@ -5,8 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"sort"
-	"strings"
+	"strconv"
+	"time"
 )
 
 //Order class representation from panaccess
@@ -15,6 +15,7 @@ type Order struct {
 	Alias          string   `json:"alias"`
 	SubscriberCode string   `json:"code"`
 	Created        string   `json:"created"`
+	Disabled       bool     `json:"disabled"`
 	ExpiryTime     string   `json:"expiryTime"`
 	FirstName      string   `json:"firstName"`
 	LastName       string   `json:"lastName"`
@@ -27,6 +28,39 @@ type Order struct {
 	ScDisabled     bool     `json:"scDisabled"`
 	Smartcards     []string `json:"smartcards"`
 	SN             string   `json:"sn"`
+	Terminated     bool     `json:"terminated"`
+	TerminatedTime string   `json:"terminationTime"`
+}
+
+//OrderStatus of an order
+type OrderStatus string
+
+//Order status values
+const (
+	OrderPending       OrderStatus = "pending"
+	OrderActive        OrderStatus = "active"
+	OrderDisabled      OrderStatus = "disabled"
+	OrderExpired       OrderStatus = "expired"
+	OrderTerminated    OrderStatus = "terminated"
+	OrderCardDefective OrderStatus = "card-defective"
+)
+
+//OrderEventType of an order timeline event
+type OrderEventType string
+
+//Order event types
+const (
+	OrderEventCreated    OrderEventType = "created"
+	OrderEventModified   OrderEventType = "modified"
+	OrderEventExpired    OrderEventType = "expired"
+	OrderEventTerminated OrderEventType = "terminated"
+)
+
+//OrderEvent of an order timeline
+type OrderEvent struct {
+	Time  time.Time
+	Type  OrderEventType
+	Order Order
 }
 
 //GetOrdersFilterResponse from panaccess
@@ -96,69 +130,144 @@ func (order *Order) GetWithFilters(pan *Panaccess, params *url.Values, groupOp s
 	return rows.OrderEntries, nil
 }
 
-//AddToSubscriber a order from panaccess
+//SmartcardSelection chooses which subscriber smartcards receive a new order
+type SmartcardSelection int
+
+//Smartcard selection modes for AddToSubscriberWithSelection
+const (
+	//SelectMissingProduct cards of subscriber which don't have the product yet,
+	//a subscriber without cards gets the order without cards
+	SelectMissingProduct SmartcardSelection = iota
+	//SelectAllSmartcards every card of subscriber, chosen by panaccess
+	SelectAllSmartcards
+	//SelectSpecifiedSmartcards only the given cards
+	SelectSpecifiedSmartcards
+	//SelectNoSmartcards order without cards
+	SelectNoSmartcards
+)
+
+//UnlimitedExpiryTime used as expiryTime for orders which shouldn't expire
+const UnlimitedExpiryTime = "2099-12-31 23:59:59"
+
+//ErrNothingToChange returned when the order wouldn't reach any smartcard
+var ErrNothingToChange = errors.New("All smartcards already have the product")
+
+//AddToSubscriber a order from panaccess to the subscriber cards missing the product
 func (order *Order) AddToSubscriber(pan *Panaccess, params *url.Values) error {
+	return order.AddToSubscriberWithSelection(pan, params, SelectMissingProduct, nil)
+}
+
+//AddToSubscriberWithSelection a order from panaccess choosing which smartcards receive it.
+//cards is only used with SelectSpecifiedSmartcards.
+func (order *Order) AddToSubscriberWithSelection(pan *Panaccess, params *url.Values, mode SmartcardSelection, cards []string) error {
 	//Verify Fields
 	if params.Get("productId") == "" || params.Get("subscriberCode") == "" || params.Get("activationTime") == "" || params.Get("expiryTime") == "" {
 		return errors.New("Please fill all required fields")
 	}
-	(*params).Set("onlySpecifiedSmartcards", "true")
+	if mode == SelectSpecifiedSmartcards && len(cards) == 0 {
+		return errors.New("Please specify the smartcards")
+	}
 	//Verify if user exists
-	if params.Get("subscriberCode") != "" {
-		resp, err := pan.Call(
-			"subscriberExists",
-			params,
-		)
+	resp, err := pan.Call(
+		"subscriberExists",
+		params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	params.Del("smartcards[]")
+	switch mode {
+	case SelectAllSmartcards:
+		(*params).Set("onlySpecifiedSmartcards", "false")
+	case SelectNoSmartcards:
+		(*params).Set("onlySpecifiedSmartcards", "true")
+	case SelectSpecifiedSmartcards, SelectMissingProduct:
+		(*params).Set("onlySpecifiedSmartcards", "true")
+		//Get Subscriber smartcards
+		sub := Subscriber{
+			SubscriberCode: params.Get("subscriberCode"),
+		}
+		subCards, err := sub.GetSmartcards(pan)
 		if err != nil {
 			return err
 		}
-		if !resp.Success {
-			return errors.New(resp.ErrorMessage)
+		selected, err := selectSmartcards(pan, params.Get("productId"), subCards, mode, cards)
+		if err != nil {
+			return err
 		}
+		for _, sn := range selected {
+			(*params).Add("smartcards[]", sn)
+		}
+	default:
+		return fmt.Errorf("Unknown smartcard selection %d", mode)
 	}
-	//Get Subscriber smartcards
-	sub := Subscriber{
-		SubscriberCode: params.Get("subscriberCode"),
-	}
-	cards, err := sub.GetSmartcards(pan)
+	//Send data to make new order
+	resp, err = pan.Call(
+		"addFlexibleOrderToSubscriber",
+		params)
 	if err != nil {
 		return err
 	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	return nil
+}
+
+//selectSmartcards of subscriber which should receive productID
+func selectSmartcards(pan *Panaccess, productID string, subCards []Smartcard, mode SmartcardSelection, cards []string) ([]string, error) {
+	if mode == SelectSpecifiedSmartcards {
+		owned := map[string]bool{}
+		for _, card := range subCards {
+			owned[card.SN] = true
+		}
+		for _, sn := range cards {
+			if !owned[sn] {
+				return nil, fmt.Errorf("Smartcard %s doesn't belong to subscriber", sn)
+			}
+		}
+		return cards, nil
+	}
+	//Subscriber without cards gets the order without cards, as SelectNoSmartcards
+	if len(subCards) == 0 {
+		return []string{}, nil
+	}
 	//Get Product Name
-	prod := Product{}
-	prods, err := prod.GetWithFilter(pan, &url.Values{}, "AND", []Rule{
-		{
-			Field: "productId",
-			OP:    "eq",
-			Data:  params.Get("productId"),
-		},
-	})
+	prod, err := findProduct(pan, productID)
 	if err != nil {
-		return err
-	}
-	if len(prods) == 0 {
-		return errors.New("ProductId not found")
+		return nil, err
 	}
 	//Add card to product if hasn't
-	fmt.Printf("Cards: %v\n", cards)
-	for _, card := range cards {
-		fmt.Printf("Products: %v | Name: %v\n", card.Products, prods[0].Name)
-		fmt.Printf("Len1: %v | Len2: %v\n", sort.SearchStrings(card.Products, prods[0].Name), len(card.Products))
+	selected := []string{}
+	for _, card := range subCards {
 		found := false
 		for _, v := range card.Products {
-			if strings.Compare(v, prods[0].Name) == 0 {
+			if v == prod.Name {
 				found = true
+				break
 			}
 		}
 		if !found {
-			(*params).Add("smartcards[]", card.SN)
+			selected = append(selected, card.SN)
 		}
 	}
-	//Send data to make new subscriber
-	fmt.Println(*params)
+	if len(selected) == 0 {
+		return nil, ErrNothingToChange
+	}
+	return selected, nil
+}
+
+//RemoveFromSubscriber order from panaccess
+func (order *Order) RemoveFromSubscriber(pan *Panaccess, sub *Subscriber) error {
+	params := url.Values{}
+	params.Add("orderId", fmt.Sprint(order.ID))
+	params.Add("subscriberCode", sub.SubscriberCode)
 	resp, err := pan.Call(
-		"addFlexibleOrderToSubscriber",
-		params)
+		"terminateOrderOfSubscriber",
+		&params)
 	if err != nil {
 		return err
 	}
@@ -168,13 +277,58 @@ func (order *Order) AddToSubscriber(pan *Panaccess, params *url.Values) error {
 	return nil
 }
 
-//RemoveFromSubscriber order from panaccess
-func (order *Order) RemoveFromSubscriber(pan *Panaccess, sub *Subscriber) error {
+//findProduct by ID using the panaccess product catalog when available
+func findProduct(pan *Panaccess, productID string) (*Product, error) {
+	if pan.Products != nil {
+		if !pan.Products.Loaded() {
+			err := pan.Products.Load(pan)
+			if err != nil {
+				return nil, err
+			}
+		}
+		id, err := strconv.Atoi(productID)
+		if err != nil {
+			return nil, err
+		}
+		prod, err := pan.Products.ByID(id)
+		if err == nil {
+			return prod, nil
+		}
+		//Created after the last Load, ask panaccess below
+	}
+	prod := Product{}
+	prods, err := prod.GetWithFilter(pan, &url.Values{}, "AND", []Rule{
+		{
+			Field: "productId",
+			OP:    "eq",
+			Data:  productID,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(prods) == 0 {
+		return nil, &NotFoundError{Entity: "Product", Key: productID}
+	}
+	if pan.Products != nil {
+		pan.Products.update(prods[0])
+	}
+	return &prods[0], nil
+}
+
+//Extend order expiry time at panaccess
+func (order *Order) Extend(pan *Panaccess, until time.Time) error {
+	//Verify Fields
+	if order.ID == 0 || order.SubscriberCode == "" {
+		return errors.New("Please fill all required fields")
+	}
+	expiry := until.Format(timeLayout)
 	params := url.Values{}
 	params.Add("orderId", fmt.Sprint(order.ID))
-	params.Add("subscriberCode", sub.SubscriberCode)
+	params.Add("subscriberCode", order.SubscriberCode)
+	params.Add("expiryTime", expiry)
 	resp, err := pan.Call(
-		"terminateOrderOfSubscriber",
+		"extendOrderOfSubscriber",
 		&params)
 	if err != nil {
 		return err
@@ -182,5 +336,123 @@ func (order *Order) RemoveFromSubscriber(pan *Panaccess, sub *Subscriber) error
 	if !resp.Success {
 		return errors.New(resp.ErrorMessage)
 	}
+	order.ExpiryTime = expiry
 	return nil
 }
+
+//Unlimited reports if order has no expiry
+func (order *Order) Unlimited() bool {
+	return order.ExpiryTime == "" || order.ExpiryTime == UnlimitedExpiryTime
+}
+
+//Renew order by calendar years, months and days counting from its expiry time,
+//or from now if already expired. Unlimited orders are left untouched.
+func (order *Order) Renew(pan *Panaccess, years, months, days int) error {
+	if order.Unlimited() {
+		return nil
+	}
+	from := time.Now()
+	expiry, err := time.ParseInLocation(timeLayout, order.ExpiryTime, time.Local)
+	if err != nil {
+		return err
+	}
+	if expiry.After(from) {
+		from = expiry
+	}
+	return order.Extend(pan, from.AddDate(years, months, days))
+}
+
+//parseTime from panaccess format, empty or invalid dates return zero time
+func parseTime(value string) time.Time {
+	t, err := time.ParseInLocation(timeLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+//Status of order, the first matching rule wins:
+//terminated, expired, card-defective, disabled (order or card), pending (activation in the future) and active
+func (order *Order) Status() OrderStatus {
+	now := time.Now()
+	if order.Terminated {
+		return OrderTerminated
+	}
+	expiry := parseTime(order.ExpiryTime)
+	if !expiry.IsZero() && expiry.Before(now) {
+		return OrderExpired
+	}
+	if order.ScDefect {
+		return OrderCardDefective
+	}
+	if order.Disabled || order.ScDisabled {
+		return OrderDisabled
+	}
+	if parseTime(order.ActivationTime).After(now) {
+		return OrderPending
+	}
+	return OrderActive
+}
+
+//GetAllWithFilters order from panaccess following pagination
+func (order *Order) GetAllWithFilters(pan *Panaccess, params *url.Values, groupOp string, filters []Rule) ([]Order, error) {
+	if filters == nil {
+		filters = []Rule{}
+	}
+	all := []Order{}
+	err := pan.callAllPages("getListOfOrders", params, groupOp, filters, func(answer []byte) (int, int, error) {
+		var rows GetOrdersFilterResponse
+		err := json.Unmarshal(answer, &rows)
+		all = append(all, rows.OrderEntries...)
+		return len(rows.OrderEntries), rows.Count, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+//GetWithFiltersByStatus every order from panaccess matching filters keeping only the given statuses.
+//No statuses means every order.
+func (order *Order) GetWithFiltersByStatus(pan *Panaccess, params *url.Values, groupOp string, filters []Rule, statuses ...OrderStatus) ([]Order, error) {
+	orders, err := order.GetAllWithFilters(pan, params, groupOp, filters)
+	if err != nil {
+		return nil, err
+	}
+	if len(statuses) == 0 {
+		return orders, nil
+	}
+	wanted := map[OrderStatus]bool{}
+	for _, status := range statuses {
+		wanted[status] = true
+	}
+	ret := []Order{}
+	for _, o := range orders {
+		if wanted[o.Status()] {
+			ret = append(ret, o)
+		}
+	}
+	return ret, nil
+}
+
+//Events of order for a timeline
+func (order *Order) Events() []OrderEvent {
+	events := []OrderEvent{}
+	add := func(value string, eventType OrderEventType) {
+		t := parseTime(value)
+		if !t.IsZero() {
+			events = append(events, OrderEvent{Time: t, Type: eventType, Order: *order})
+		}
+	}
+	add(order.Created, OrderEventCreated)
+	if order.Modified != order.Created {
+		add(order.Modified, OrderEventModified)
+	}
+	if expiry := parseTime(order.ExpiryTime); !expiry.IsZero() && expiry.Before(time.Now()) {
+		add(order.ExpiryTime, OrderEventExpired)
+	}
+	if order.Terminated {
+		add(order.TerminatedTime, OrderEventTerminated)
+	}
+	return events
+}
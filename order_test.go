@@ -0,0 +1,81 @@
+package panaccess
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrderStatus(t *testing.T) {
+	past := time.Now().AddDate(0, 0, -1).Format(timeLayout)
+	future := time.Now().AddDate(0, 0, 1).Format(timeLayout)
+	tests := []struct {
+		name  string
+		order Order
+		want  OrderStatus
+	}{
+		{"active", Order{ActivationTime: past, ExpiryTime: future}, OrderActive},
+		{"no dates", Order{}, OrderActive},
+		{"pending", Order{ActivationTime: future}, OrderPending},
+		{"expired", Order{ActivationTime: past, ExpiryTime: past}, OrderExpired},
+		{"disabled", Order{ExpiryTime: future, Disabled: true}, OrderDisabled},
+		{"card disabled", Order{ExpiryTime: future, ScDisabled: true}, OrderDisabled},
+		{"card defective", Order{ExpiryTime: future, ScDefect: true, Disabled: true}, OrderCardDefective},
+		{"terminated", Order{ExpiryTime: past, Terminated: true, ScDefect: true}, OrderTerminated},
+		{"expired before defective", Order{ExpiryTime: past, ScDefect: true}, OrderExpired},
+		{"disabled before pending", Order{ActivationTime: future, Disabled: true}, OrderDisabled},
+	}
+	for _, test := range tests {
+		if got := test.order.Status(); got != test.want {
+			t.Errorf("%s: Status() = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRenewUnlimitedOrder(t *testing.T) {
+	//No servers, any call to panaccess fails
+	pan := &Panaccess{}
+	for _, expiry := range []string{"", UnlimitedExpiryTime} {
+		order := Order{ID: 1, SubscriberCode: "SUB", ExpiryTime: expiry}
+		err := order.Renew(pan, 0, 1, 0)
+		if err != nil {
+			t.Errorf("Renew with expiry %q: %v", expiry, err)
+		}
+		if order.ExpiryTime != expiry {
+			t.Errorf("Renew changed expiry %q to %q", expiry, order.ExpiryTime)
+		}
+	}
+}
+
+func TestSelectSmartcards(t *testing.T) {
+	//No servers, the product lookup must not be needed
+	pan := &Panaccess{}
+	got, err := selectSmartcards(pan, "1", nil, SelectMissingProduct, nil)
+	if err != nil || len(got) != 0 {
+		t.Errorf("subscriber without cards = %v, %v, want no cards and no error", got, err)
+	}
+	subCards := []Smartcard{{SN: "1"}, {SN: "2"}}
+	got, err = selectSmartcards(pan, "1", subCards, SelectSpecifiedSmartcards, []string{"2"})
+	if err != nil || len(got) != 1 || got[0] != "2" {
+		t.Errorf("specified cards = %v, %v, want [2]", got, err)
+	}
+	_, err = selectSmartcards(pan, "1", subCards, SelectSpecifiedSmartcards, []string{"3"})
+	if err == nil {
+		t.Error("card of another subscriber was accepted")
+	}
+}
+
+func TestSelectSmartcardsMissingProduct(t *testing.T) {
+	pan := &Panaccess{Products: &ProductCatalog{}}
+	pan.Products.update(Product{ID: 1, Name: "Basic"})
+	pan.Products.loadedAt = time.Now()
+	subCards := []Smartcard{{SN: "1", Products: []string{"Basic"}}, {SN: "2"}}
+	got, err := selectSmartcards(pan, "1", subCards, SelectMissingProduct, nil)
+	if err != nil || len(got) != 1 || got[0] != "2" {
+		t.Errorf("missing product = %v, %v, want [2]", got, err)
+	}
+	subCards[1].Products = []string{"Basic"}
+	_, err = selectSmartcards(pan, "1", subCards, SelectMissingProduct, nil)
+	if err != ErrNothingToChange {
+		t.Errorf("every card has the product, error = %v, want ErrNothingToChange", err)
+	}
+}
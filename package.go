@@ -0,0 +1,82 @@
+package panaccess
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+)
+
+//GetListOfPackagesResponse from panaccess
+type GetListOfPackagesResponse struct {
+	Count          int       `json:"count"`
+	PackageEntries []Package `json:"packageEntries"`
+}
+
+//Package class representation from panaccess
+type Package struct {
+	ID          int    `json:"packageId"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+//Get packages from panaccess
+func (pkg *Package) Get(pan *Panaccess, params *url.Values) ([]Package, error) {
+	//Everything has a limit
+	if (*params).Get("limit") == "" {
+		(*params).Add("limit", "1000")
+	}
+	//Call Function
+	resp, err := pan.Call(
+		"getListOfPackages",
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	//Retrieve all rows and parse as a slice of Package
+	var rows GetListOfPackagesResponse
+	bodyBytes, err := json.Marshal(resp.Answer)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bodyBytes, &rows)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+	return rows.PackageEntries, nil
+}
+
+//GetWithFilter packages from panaccess
+func (pkg *Package) GetWithFilter(pan *Panaccess, params *url.Values, groupOp string, filters []Rule) ([]Package, error) {
+	//Everything has a limit
+	if (*params).Get("limit") == "" {
+		(*params).Add("limit", "1000")
+	}
+	//Call Function
+	resp, err := pan.CallWithFilters(
+		"getListOfPackages",
+		params,
+		groupOp,
+		filters,
+	)
+	if err != nil {
+		return nil, err
+	}
+	//Retrieve all rows and parse as a slice of Package
+	var rows GetListOfPackagesResponse
+	bodyBytes, err := json.Marshal(resp.Answer)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bodyBytes, &rows)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+	return rows.PackageEntries, nil
+}
@@ -19,6 +19,8 @@ type Panaccess struct {
 	Token     string
 	SessionID string
 	HTTP      *http.Client
+	//Products cache used by order workflows, optional
+	Products *ProductCatalog
 }
 
 //Rule of a query
@@ -42,7 +44,8 @@ type Filters struct {
 }
 
 const (
-	salt = "_panaccess" //appended to password
+	salt       = "_panaccess"          //appended to password
+	timeLayout = "2006-01-02 15:04:05" //date format used by panaccess
 )
 
 //APIResponse marshal JSON output to struct
@@ -68,6 +71,16 @@ type KeyValuePair struct {
 	Value string `json:"value"`
 }
 
+//NotFoundError returned when a lookup doesn't match any entry
+type NotFoundError struct {
+	Entity string
+	Key    string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.Entity, e.Key)
+}
+
 //Login in system
 func (p *Panaccess) Login() error {
 	//Add password salt
@@ -262,3 +275,46 @@ func (p *Panaccess) CallWithFilters(funcName string, parameters *url.Values, fil
 	}
 	return &apiResponse, nil
 }
+
+//callAllPages calls funcName page by page using offset until count rows are read.
+//filters nil calls without filters. decode parses a page answer returning its rows and the total count.
+func (p *Panaccess) callAllPages(funcName string, params *url.Values, groupOp string, filters []Rule, decode func(answer []byte) (int, int, error)) error {
+	//Everything has a limit
+	if (*params).Get("limit") == "" {
+		(*params).Set("limit", "1000")
+	}
+	read := 0
+	for {
+		page := url.Values{}
+		for k, v := range *params {
+			page[k] = append([]string{}, v...)
+		}
+		page.Set("offset", fmt.Sprint(read))
+		//Call Function
+		var resp *APIResponse
+		var err error
+		if filters == nil {
+			resp, err = p.Call(funcName, &page)
+		} else {
+			resp, err = p.CallWithFilters(funcName, &page, groupOp, filters)
+		}
+		if err != nil {
+			return err
+		}
+		if !resp.Success {
+			return errors.New(resp.ErrorMessage)
+		}
+		bodyBytes, err := json.Marshal(resp.Answer)
+		if err != nil {
+			return err
+		}
+		rows, count, err := decode(bodyBytes)
+		if err != nil {
+			return err
+		}
+		read += rows
+		if rows == 0 || read >= count {
+			return nil
+		}
+	}
+}
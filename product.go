@@ -3,6 +3,7 @@ package panaccess
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/url"
 )
 
@@ -14,9 +15,13 @@ type GetListOfProductsReponse struct {
 
 //Product class representation from panaccess
 type Product struct {
-	ID      int    `json:"productId"`
-	Name    string `json:"name"`
-	Deleted bool   `json:"deleted"`
+	ID       int     `json:"productId"`
+	Name     string  `json:"name"`
+	Deleted  bool    `json:"deleted"`
+	Packages []int   `json:"packages"`
+	Price    float64 `json:"price"`
+	Duration int     `json:"duration"`
+	Type     string  `json:"type"`
 }
 
 //Get product from panaccess
@@ -45,6 +50,21 @@ func (prod *Product) Get(pan *Panaccess, params *url.Values) ([]Product, error)
 	return rows.ProductEntries, nil
 }
 
+//GetAll products from panaccess following pagination
+func (prod *Product) GetAll(pan *Panaccess, params *url.Values) ([]Product, error) {
+	all := []Product{}
+	err := pan.callAllPages("getListOfProducts", params, "", nil, func(answer []byte) (int, int, error) {
+		var rows GetListOfProductsReponse
+		err := json.Unmarshal(answer, &rows)
+		all = append(all, rows.ProductEntries...)
+		return len(rows.ProductEntries), rows.Count, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
 //GetWithFilter product from panaccess
 func (prod *Product) GetWithFilter(pan *Panaccess, params *url.Values, groupOp string, filters []Rule) ([]Product, error) {
 	//Everything has a limit
@@ -72,3 +92,153 @@ func (prod *Product) GetWithFilter(pan *Panaccess, params *url.Values, groupOp s
 	}
 	return rows.ProductEntries, nil
 }
+
+//GetPackages included in product
+func (prod *Product) GetPackages(pan *Panaccess) ([]Package, error) {
+	if len(prod.Packages) == 0 {
+		return []Package{}, nil
+	}
+	pkg := Package{}
+	pkgs, err := pkg.Get(pan, &url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	included := map[int]bool{}
+	for _, id := range prod.Packages {
+		included[id] = true
+	}
+	ret := []Package{}
+	for _, p := range pkgs {
+		if included[p.ID] {
+			ret = append(ret, p)
+		}
+	}
+	return ret, nil
+}
+
+//Create product at panaccess, ID is filled with the new one
+func (prod *Product) Create(pan *Panaccess) error {
+	//Verify Fields
+	if prod.Name == "" {
+		return errors.New("Please fill all required fields")
+	}
+	params := url.Values{}
+	params.Add("name", prod.Name)
+	for _, id := range prod.Packages {
+		params.Add("packages[]", fmt.Sprint(id))
+	}
+	//Call Function
+	resp, err := pan.Call(
+		"addProduct",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	//Answer is the new productId
+	id, ok := resp.Answer.(float64)
+	if !ok {
+		return errors.New("Unexpected answer from addProduct")
+	}
+	prod.ID = int(id)
+	prod.updateCatalog(pan)
+	return nil
+}
+
+//Rename product at panaccess
+func (prod *Product) Rename(pan *Panaccess, name string) error {
+	//Verify Fields
+	if name == "" {
+		return errors.New("Please fill all required fields")
+	}
+	params := url.Values{}
+	params.Add("productId", fmt.Sprint(prod.ID))
+	params.Add("name", name)
+	//Call Function
+	resp, err := pan.Call(
+		"renameProduct",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	prod.Name = name
+	prod.updateCatalog(pan)
+	return nil
+}
+
+//SetPackages replaces the packages included in product at panaccess
+func (prod *Product) SetPackages(pan *Panaccess, packages []int) error {
+	params := url.Values{}
+	params.Add("productId", fmt.Sprint(prod.ID))
+	for _, id := range packages {
+		params.Add("packages[]", fmt.Sprint(id))
+	}
+	//Call Function
+	resp, err := pan.Call(
+		"setPackagesOfProduct",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	prod.Packages = packages
+	prod.updateCatalog(pan)
+	return nil
+}
+
+//Delete product at panaccess, it can be recovered with Undelete
+func (prod *Product) Delete(pan *Panaccess) error {
+	params := url.Values{}
+	params.Add("productId", fmt.Sprint(prod.ID))
+	//Call Function
+	resp, err := pan.Call(
+		"deleteProduct",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	prod.Deleted = true
+	prod.updateCatalog(pan)
+	return nil
+}
+
+//Undelete product at panaccess
+func (prod *Product) Undelete(pan *Panaccess) error {
+	params := url.Values{}
+	params.Add("productId", fmt.Sprint(prod.ID))
+	//Call Function
+	resp, err := pan.Call(
+		"undeleteProduct",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	prod.Deleted = false
+	prod.updateCatalog(pan)
+	return nil
+}
+
+//updateCatalog keeps the panaccess product catalog in sync after a change
+func (prod *Product) updateCatalog(pan *Panaccess) {
+	if pan.Products != nil {
+		pan.Products.update(*prod)
+	}
+}
@@ -0,0 +1,211 @@
+package panaccess
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+//GetListOfRegionsResponse from panaccess
+type GetListOfRegionsResponse struct {
+	Count         int      `json:"count"`
+	RegionEntries []Region `json:"regionEntries"`
+}
+
+//Region class representation from panaccess
+type Region struct {
+	ID   int    `json:"regionId"`
+	Name string `json:"name"`
+}
+
+//Get regions from panaccess
+func (region *Region) Get(pan *Panaccess, params *url.Values) ([]Region, error) {
+	//Everything has a limit
+	if (*params).Get("limit") == "" {
+		(*params).Add("limit", "1000")
+	}
+	//Call Function
+	resp, err := pan.Call(
+		"getListOfRegions",
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	//Retrieve all rows and parse as a slice of Region
+	var rows GetListOfRegionsResponse
+	bodyBytes, err := json.Marshal(resp.Answer)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bodyBytes, &rows)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+	return rows.RegionEntries, nil
+}
+
+//GetByID region from panaccess
+func (region *Region) GetByID(pan *Panaccess, id int) (*Region, error) {
+	regions, err := region.GetWithFilter(pan, &url.Values{}, "AND", []Rule{
+		{
+			Field: "regionId",
+			OP:    "eq",
+			Data:  fmt.Sprint(id),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range regions {
+		if regions[i].ID == id {
+			return &regions[i], nil
+		}
+	}
+	return nil, &NotFoundError{Entity: "Region", Key: fmt.Sprint(id)}
+}
+
+//GetWithFilter regions from panaccess
+func (region *Region) GetWithFilter(pan *Panaccess, params *url.Values, groupOp string, filters []Rule) ([]Region, error) {
+	//Everything has a limit
+	if (*params).Get("limit") == "" {
+		(*params).Add("limit", "1000")
+	}
+	//Call Function
+	resp, err := pan.CallWithFilters(
+		"getListOfRegions",
+		params,
+		groupOp,
+		filters,
+	)
+	if err != nil {
+		return nil, err
+	}
+	//Retrieve all rows and parse as a slice of Region
+	var rows GetListOfRegionsResponse
+	bodyBytes, err := json.Marshal(resp.Answer)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bodyBytes, &rows)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+	return rows.RegionEntries, nil
+}
+
+//Create region at panaccess, ID is filled with the new one
+func (region *Region) Create(pan *Panaccess) error {
+	//Verify Fields
+	if region.Name == "" {
+		return errors.New("Please fill all required fields")
+	}
+	params := url.Values{}
+	params.Add("name", region.Name)
+	//Call Function
+	resp, err := pan.Call(
+		"addRegion",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	//Answer is the new regionId
+	id, ok := resp.Answer.(float64)
+	if !ok {
+		return errors.New("Unexpected answer from addRegion")
+	}
+	region.ID = int(id)
+	return nil
+}
+
+//Update region at panaccess
+func (region *Region) Update(pan *Panaccess) error {
+	//Verify Fields
+	if region.ID == 0 || region.Name == "" {
+		return errors.New("Please fill all required fields")
+	}
+	params := url.Values{}
+	params.Add("regionId", fmt.Sprint(region.ID))
+	params.Add("name", region.Name)
+	//Call Function
+	resp, err := pan.Call(
+		"updateRegion",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	return nil
+}
+
+//filter matching everything of the region
+func (region *Region) filter() []Rule {
+	return []Rule{
+		{
+			Field: "regionId",
+			OP:    "eq",
+			Data:  fmt.Sprint(region.ID),
+		},
+	}
+}
+
+//GetSubscribers of region
+func (region *Region) GetSubscribers(pan *Panaccess, params *url.Values) ([]Subscriber, error) {
+	sub := Subscriber{}
+	return sub.GetWithFilters(pan, params, "AND", region.filter())
+}
+
+//GetSmartcards of region
+func (region *Region) GetSmartcards(pan *Panaccess, params *url.Values) ([]Smartcard, error) {
+	card := Smartcard{}
+	return card.GetWithFilter(pan, params, "AND", region.filter())
+}
+
+//count rows of funcName belonging to region without retrieving them
+func (region *Region) count(pan *Panaccess, funcName string) (int, error) {
+	params := url.Values{}
+	params.Add("limit", "1")
+	resp, err := pan.CallWithFilters(funcName, &params, "AND", region.filter())
+	if err != nil {
+		return 0, err
+	}
+	if !resp.Success {
+		return 0, errors.New(resp.ErrorMessage)
+	}
+	var rows struct {
+		Count int `json:"count"`
+	}
+	bodyBytes, err := json.Marshal(resp.Answer)
+	if err != nil {
+		return 0, err
+	}
+	err = json.Unmarshal(bodyBytes, &rows)
+	if err != nil {
+		return 0, err
+	}
+	return rows.Count, nil
+}
+
+//CountSubscribers of region
+func (region *Region) CountSubscribers(pan *Panaccess) (int, error) {
+	return region.count(pan, "getListOfExtendedSubscribers")
+}
+
+//CountSmartcards of region
+func (region *Region) CountSmartcards(pan *Panaccess) (int, error) {
+	return region.count(pan, "getListOfSmartcards")
+}
@@ -0,0 +1,86 @@
+package panaccess
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+)
+
+//GetListOfSetTopBoxesResponse from panaccess
+type GetListOfSetTopBoxesResponse struct {
+	Count            int         `json:"count"`
+	SetTopBoxEntries []SetTopBox `json:"stbEntries"`
+}
+
+//SetTopBox class representation from panaccess
+type SetTopBox struct {
+	SN              string `json:"sn"`
+	MAC             string `json:"mac"`
+	Model           string `json:"stbModel"`
+	Vendor          string `json:"stbVendor"`
+	Chipset         string `json:"stbChipset"`
+	PairedSmartcard string `json:"pairedSmartcard"`
+	SubscriberCode  string `json:"subscriberCode"`
+}
+
+//Get set-top boxes from panaccess
+func (box *SetTopBox) Get(pan *Panaccess, params *url.Values) ([]SetTopBox, error) {
+	//Everything has a limit
+	if (*params).Get("limit") == "" {
+		(*params).Add("limit", "1000")
+	}
+	//Call Function
+	resp, err := pan.Call(
+		"getListOfSetTopBoxes",
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	//Retrieve all rows and parse as a slice of SetTopBox
+	var rows GetListOfSetTopBoxesResponse
+	bodyBytes, err := json.Marshal(resp.Answer)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bodyBytes, &rows)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+	return rows.SetTopBoxEntries, nil
+}
+
+//GetWithFilter set-top boxes from panaccess
+func (box *SetTopBox) GetWithFilter(pan *Panaccess, params *url.Values, groupOp string, filters []Rule) ([]SetTopBox, error) {
+	//Everything has a limit
+	if (*params).Get("limit") == "" {
+		(*params).Add("limit", "1000")
+	}
+	//Call Function
+	resp, err := pan.CallWithFilters(
+		"getListOfSetTopBoxes",
+		params,
+		groupOp,
+		filters,
+	)
+	if err != nil {
+		return nil, err
+	}
+	//Retrieve all rows and parse as a slice of SetTopBox
+	var rows GetListOfSetTopBoxesResponse
+	bodyBytes, err := json.Marshal(resp.Answer)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bodyBytes, &rows)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+	return rows.SetTopBoxEntries, nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"time"
 )
 
 //Smartcard class representation from panaccess
@@ -39,6 +40,25 @@ type Smartcard struct {
 	SubscriberCode          string   `json:"subscriberCode"`
 }
 
+//Smartcard errors
+var (
+	ErrSmartcardDisabled = errors.New("Smartcard is disabled")
+	ErrSmartcardDefect   = errors.New("Smartcard is defective")
+	ErrInvalidPIN        = errors.New("PIN must be 4 digits")
+)
+
+//CommandResult of a command sent to one smartcard of a group
+type CommandResult struct {
+	SN string
+	//Error is ErrSmartcardDisabled, ErrSmartcardDefect or ErrConfigProtected if the card was skipped
+	Error error
+}
+
+//Skipped reports if the command wasn't sent because of the card state
+func (result CommandResult) Skipped() bool {
+	return result.Error == ErrSmartcardDisabled || result.Error == ErrSmartcardDefect || result.Error == ErrConfigProtected
+}
+
 //Smartcards array of smartcard
 type Smartcards []Smartcard
 
@@ -60,6 +80,53 @@ type GetSmartcardOrdersResponse struct {
 	Answer  []Order `json:"answer"`
 }
 
+//Steps of a smartcard replacement
+const (
+	ReplaceStepValidate      = "validate"
+	ReplaceStepAssign        = "assign"
+	ReplaceStepMigrateOrders = "migrateOrders"
+	ReplaceStepAlias         = "alias"
+	ReplaceStepMarkDefect    = "markDefect"
+	ReplaceStepBlacklist     = "blacklist"
+)
+
+//ReplaceStep result of a single replacement step
+type ReplaceStep struct {
+	Name  string
+	Done  bool
+	Error error
+}
+
+//ReplaceReport of a smartcard replacement, pass it again to resume
+type ReplaceReport struct {
+	OldSN          string
+	NewSN          string
+	SubscriberCode string
+	Steps          []ReplaceStep
+}
+
+//done reports if step has been already completed
+func (report *ReplaceReport) done(name string) bool {
+	for _, step := range report.Steps {
+		if step.Name == name && step.Done {
+			return true
+		}
+	}
+	return false
+}
+
+//record step result, replacing any previous attempt
+func (report *ReplaceReport) record(name string, err error) {
+	step := ReplaceStep{Name: name, Done: err == nil, Error: err}
+	for i := range report.Steps {
+		if report.Steps[i].Name == name {
+			report.Steps[i] = step
+			return
+		}
+	}
+	report.Steps = append(report.Steps, step)
+}
+
 //Get smartcard from panaccess
 func (card *Smartcard) Get(pan *Panaccess, params *url.Values) ([]Smartcard, error) {
 	//Everything has a limit
@@ -135,8 +202,13 @@ func (card *Smartcard) GetUnused(pan *Panaccess, params *url.Values) ([]Smartcar
 	}
 	var rows Smartcards
 	bodyBytes, err := json.Marshal(resp.Answer)
-	fmt.Println(string(bodyBytes))
+	if err != nil {
+		return nil, err
+	}
 	err = json.Unmarshal(bodyBytes, &rows)
+	if err != nil {
+		return nil, err
+	}
 	return rows, nil
 }
 
@@ -177,3 +249,660 @@ func (card *Smartcard) Lock(pan *Panaccess) error {
 	}
 	return nil
 }
+
+//AssignToSubscriber smartcard at panaccess
+func (card *Smartcard) AssignToSubscriber(pan *Panaccess, sub *Subscriber) error {
+	//Verify Fields
+	if sub == nil || sub.SubscriberCode == "" || card.SN == "" {
+		return errors.New("Please fill all required fields")
+	}
+	//Params
+	params := url.Values{}
+	params.Add("smartcardId", card.SN)
+	params.Add("subscriberCode", sub.SubscriberCode)
+	//Call Function
+	resp, err := pan.Call(
+		"addSmartcardToSubscriber",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	card.SubscriberCode = sub.SubscriberCode
+	return nil
+}
+
+//RemoveFromSubscriber smartcard at panaccess
+func (card *Smartcard) RemoveFromSubscriber(pan *Panaccess) error {
+	//Verify Fields
+	if card.SN == "" {
+		return errors.New("Please fill all required fields")
+	}
+	//Params
+	params := url.Values{}
+	params.Add("smartcardId", card.SN)
+	//Call Function
+	resp, err := pan.Call(
+		"removeSmartcardFromSubscriber",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	card.SubscriberCode = ""
+	return nil
+}
+
+//action calls a panaccess function which only needs the smartcard SN
+func (card *Smartcard) action(pan *Panaccess, funcName string) error {
+	//Params
+	params := url.Values{}
+	params.Add("smartcardId", card.SN)
+	//Call Function
+	resp, err := pan.Call(
+		funcName,
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	return nil
+}
+
+//ReplaceSmartcard moves subscriber, orders and alias of card to newCard and retires card.
+//If a step fails the returned report can be passed again to resume the workflow.
+func (card *Smartcard) ReplaceSmartcard(pan *Panaccess, newCard *Smartcard, report *ReplaceReport) (*ReplaceReport, error) {
+	if report == nil {
+		report = &ReplaceReport{
+			OldSN:          card.SN,
+			NewSN:          newCard.SN,
+			SubscriberCode: card.SubscriberCode,
+		}
+	}
+	if report.OldSN != card.SN || report.NewSN != newCard.SN {
+		return report, errors.New("Report doesn't belong to these smartcards")
+	}
+	sub := Subscriber{SubscriberCode: report.SubscriberCode}
+	//Validate new card is unused
+	if !report.done(ReplaceStepValidate) {
+		var err error
+		if sub.SubscriberCode == "" {
+			err = errors.New("Smartcard has no subscriber")
+		} else {
+			var cards []Smartcard
+			cards, err = card.GetWithFilter(pan, &url.Values{}, "AND", []Rule{
+				{
+					Field: "sn",
+					OP:    "eq",
+					Data:  newCard.SN,
+				},
+			})
+			if err == nil && len(cards) == 0 {
+				err = &NotFoundError{Entity: "Smartcard", Key: newCard.SN}
+			} else if err == nil && cards[0].SubscriberCode != "" {
+				err = fmt.Errorf("Smartcard %s is already assigned to %s", newCard.SN, cards[0].SubscriberCode)
+			}
+		}
+		report.record(ReplaceStepValidate, err)
+		if err != nil {
+			return report, err
+		}
+	}
+	//Assign new card to the same subscriber
+	if !report.done(ReplaceStepAssign) {
+		err := newCard.AssignToSubscriber(pan, &sub)
+		report.record(ReplaceStepAssign, err)
+		if err != nil {
+			return report, err
+		}
+	}
+	//Add every running product of the old card to the new one
+	if !report.done(ReplaceStepMigrateOrders) {
+		err := card.migrateOrders(pan, &sub, newCard)
+		report.record(ReplaceStepMigrateOrders, err)
+		if err != nil {
+			return report, err
+		}
+	}
+	//Keep the alias the customer knows
+	if !report.done(ReplaceStepAlias) {
+		var err error
+		if card.Alias != "" {
+			err = newCard.SetAlias(pan, card.Alias)
+		}
+		report.record(ReplaceStepAlias, err)
+		if err != nil {
+			return report, err
+		}
+	}
+	//Retire old card
+	if !report.done(ReplaceStepMarkDefect) {
+		err := card.MarkDefect(pan)
+		report.record(ReplaceStepMarkDefect, err)
+		if err != nil {
+			return report, err
+		}
+	}
+	if !report.done(ReplaceStepBlacklist) {
+		err := card.Blacklist(pan)
+		report.record(ReplaceStepBlacklist, err)
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+//migrateOrders adds the orders of card still running to newCard only.
+//Products newCard already has are skipped so the step can be resumed.
+func (card *Smartcard) migrateOrders(pan *Panaccess, sub *Subscriber, newCard *Smartcard) error {
+	orders, err := sub.GetOrders(pan, &url.Values{})
+	if err != nil {
+		return err
+	}
+	cards, err := card.GetWithFilter(pan, &url.Values{}, "AND", []Rule{
+		{
+			Field: "sn",
+			OP:    "eq",
+			Data:  newCard.SN,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		return &NotFoundError{Entity: "Smartcard", Key: newCard.SN}
+	}
+	migrated := map[string]bool{}
+	for _, name := range cards[0].Products {
+		migrated[name] = true
+	}
+	now := time.Now().Format(timeLayout)
+	for _, order := range orders {
+		if migrated[order.ProductName] || (order.ExpiryTime != "" && order.ExpiryTime < now) {
+			continue
+		}
+		for _, sn := range order.Smartcards {
+			if sn != card.SN {
+				continue
+			}
+			//Orders without expiry keep running without end on the new card
+			expiry := order.ExpiryTime
+			if expiry == "" {
+				expiry = UnlimitedExpiryTime
+			}
+			params := url.Values{}
+			params.Add("productId", fmt.Sprint(order.ProductID))
+			params.Add("subscriberCode", sub.SubscriberCode)
+			params.Add("activationTime", now)
+			params.Add("expiryTime", expiry)
+			err = order.AddToSubscriberWithSelection(pan, &params, SelectSpecifiedSmartcards, []string{newCard.SN})
+			if err != nil {
+				return err
+			}
+			migrated[order.ProductName] = true
+			break
+		}
+	}
+	return nil
+}
+
+//Blacklist smartcard at panaccess
+func (card *Smartcard) Blacklist(pan *Panaccess) error {
+	err := card.action(pan, "blacklistSmartcard")
+	if err != nil {
+		return err
+	}
+	card.Blacklisted = true
+	return nil
+}
+
+//Unblacklist smartcard at panaccess
+func (card *Smartcard) Unblacklist(pan *Panaccess) error {
+	err := card.action(pan, "unblacklistSmartcard")
+	if err != nil {
+		return err
+	}
+	card.Blacklisted = false
+	return nil
+}
+
+//MarkDefect smartcard at panaccess
+func (card *Smartcard) MarkDefect(pan *Panaccess) error {
+	err := card.action(pan, "markSmartcardAsDefect")
+	if err != nil {
+		return err
+	}
+	card.Defect = true
+	return nil
+}
+
+//UnmarkDefect smartcard at panaccess
+func (card *Smartcard) UnmarkDefect(pan *Panaccess) error {
+	err := card.action(pan, "unmarkSmartcardAsDefect")
+	if err != nil {
+		return err
+	}
+	card.Defect = false
+	return nil
+}
+
+//usable returns an error if the card can't receive commands
+func (card *Smartcard) usable() error {
+	if card.Defect {
+		return ErrSmartcardDefect
+	}
+	if card.Disabled {
+		return ErrSmartcardDisabled
+	}
+	return nil
+}
+
+//validPIN checks the PIN has 4 digits
+func validPIN(pin string) bool {
+	if len(pin) != 4 {
+		return false
+	}
+	for _, c := range pin {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+//SetPIN of smartcard at panaccess
+func (card *Smartcard) SetPIN(pan *Panaccess, pin string) error {
+	if !validPIN(pin) {
+		return ErrInvalidPIN
+	}
+	err := card.usable()
+	if err != nil {
+		return err
+	}
+	//Params
+	params := url.Values{}
+	params.Add("smartcardId", card.SN)
+	params.Add("pin", pin)
+	//Call Function
+	resp, err := pan.Call(
+		"setSmartcardPin",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	card.PIN = pin
+	return nil
+}
+
+//ResetPIN of smartcard to the default one at panaccess.
+//PIN is left unchanged, fetch the card again to read the default PIN.
+func (card *Smartcard) ResetPIN(pan *Panaccess) error {
+	err := card.usable()
+	if err != nil {
+		return err
+	}
+	return card.action(pan, "resetSmartcardPin")
+}
+
+//Pair smartcard with a set-top box at panaccess
+func (card *Smartcard) Pair(pan *Panaccess, box *SetTopBox) error {
+	//Verify Fields
+	if box == nil || box.SN == "" {
+		return errors.New("Please fill all required fields")
+	}
+	if card.PairedBox != "" {
+		return fmt.Errorf("Smartcard %s is already paired with %s", card.SN, card.PairedBox)
+	}
+	//Params
+	params := url.Values{}
+	params.Add("smartcardId", card.SN)
+	params.Add("stbSn", box.SN)
+	//Call Function
+	resp, err := pan.Call(
+		"pairSmartcardWithSetTopBox",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	card.PairedBox = box.SN
+	card.MAC = box.MAC
+	card.STBModel = box.Model
+	card.STBVendor = box.Vendor
+	card.STBChipset = box.Chipset
+	box.PairedSmartcard = card.SN
+	return nil
+}
+
+//Unpair smartcard from its set-top box at panaccess
+func (card *Smartcard) Unpair(pan *Panaccess) error {
+	//Not paired
+	if card.PairedBox == "" {
+		return nil
+	}
+	err := card.action(pan, "unpairSmartcard")
+	if err != nil {
+		return err
+	}
+	card.PairedBox = ""
+	card.MAC = ""
+	card.STBModel = ""
+	card.STBVendor = ""
+	card.STBChipset = ""
+	return nil
+}
+
+//Repair smartcard with another set-top box at panaccess
+func (card *Smartcard) Repair(pan *Panaccess, box *SetTopBox) error {
+	err := card.Unpair(pan)
+	if err != nil {
+		return err
+	}
+	return card.Pair(pan, box)
+}
+
+//GetPairedBox of smartcard
+func (card *Smartcard) GetPairedBox(pan *Panaccess) (*SetTopBox, error) {
+	if card.PairedBox == "" {
+		return nil, &NotFoundError{Entity: "SetTopBox", Key: card.SN}
+	}
+	box := SetTopBox{}
+	boxes, err := box.GetWithFilter(pan, &url.Values{}, "AND", []Rule{
+		{
+			Field: "sn",
+			OP:    "eq",
+			Data:  card.PairedBox,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(boxes) == 0 {
+		return nil, &NotFoundError{Entity: "SetTopBox", Key: card.PairedBox}
+	}
+	return &boxes[0], nil
+}
+
+//SetAlias of smartcard at panaccess
+func (card *Smartcard) SetAlias(pan *Panaccess, alias string) error {
+	//Params
+	params := url.Values{}
+	params.Add("smartcardId", card.SN)
+	params.Add("alias", alias)
+	//Call Function
+	resp, err := pan.Call(
+		"setSmartcardAlias",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	card.Alias = alias
+	return nil
+}
+
+//SetRegion of smartcard at panaccess, regionID must exist
+func (card *Smartcard) SetRegion(pan *Panaccess, regionID int) error {
+	//Validate target region
+	region := Region{}
+	target, err := region.GetByID(pan, regionID)
+	if err != nil {
+		return err
+	}
+	//Params
+	params := url.Values{}
+	params.Add("smartcardId", card.SN)
+	params.Add("regionId", fmt.Sprint(target.ID))
+	//Call Function
+	resp, err := pan.Call(
+		"setSmartcardRegion",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	card.RegionID = target.ID
+	card.RegionName = target.Name
+	return nil
+}
+
+//ResendEntitlements of smartcard at panaccess
+func (card *Smartcard) ResendEntitlements(pan *Panaccess) error {
+	err := card.usable()
+	if err != nil {
+		return err
+	}
+	return card.action(pan, "resendEntitlementsOfSmartcard")
+}
+
+//Reset smartcard at panaccess
+func (card *Smartcard) Reset(pan *Panaccess) error {
+	err := card.usable()
+	if err != nil {
+		return err
+	}
+	return card.action(pan, "resetSmartcard")
+}
+
+//SetConfig of smartcard at panaccess, protected configs are only changed with override
+func (card *Smartcard) SetConfig(pan *Panaccess, configID string, override bool) error {
+	//Verify Fields
+	if configID == "" {
+		return errors.New("Please fill all required fields")
+	}
+	if card.ConfigProtected && !override {
+		return ErrConfigProtected
+	}
+	//Params
+	params := url.Values{}
+	params.Add("smartcardId", card.SN)
+	params.Add("configId", configID)
+	params.Add("overrideProtection", fmt.Sprint(override))
+	//Call Function
+	resp, err := pan.Call(
+		"setSmartcardConfig",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	card.ConfigID = configID
+	return nil
+}
+
+//GetAll smartcards from panaccess following pagination
+func (card *Smartcard) GetAll(pan *Panaccess, params *url.Values) ([]Smartcard, error) {
+	return card.getAllPages(pan, params, "", nil)
+}
+
+//GetAllWithFilter smartcards from panaccess following pagination
+func (card *Smartcard) GetAllWithFilter(pan *Panaccess, params *url.Values, groupOp string, filters []Rule) ([]Smartcard, error) {
+	if filters == nil {
+		filters = []Rule{}
+	}
+	return card.getAllPages(pan, params, groupOp, filters)
+}
+
+//getAllPages of getListOfSmartcards
+func (card *Smartcard) getAllPages(pan *Panaccess, params *url.Values, groupOp string, filters []Rule) ([]Smartcard, error) {
+	all := []Smartcard{}
+	err := pan.callAllPages("getListOfSmartcards", params, groupOp, filters, func(answer []byte) (int, int, error) {
+		rows := GetListOfSmartcardsResponse{}
+		err := json.Unmarshal(answer, &rows)
+		all = append(all, rows.SmartcardEntries...)
+		return len(rows.SmartcardEntries), rows.Count, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+//MultiroomIssue products of a slave card not matching its master
+type MultiroomIssue struct {
+	SN string
+	//Missing products of the master the slave doesn't have
+	Missing []string
+	//Extra products of the slave the master doesn't have
+	Extra []string
+}
+
+//GetSlaves smartcards of a multiroom master
+func (card *Smartcard) GetSlaves(pan *Panaccess) ([]Smartcard, error) {
+	return card.GetWithFilter(pan, &url.Values{}, "AND", []Rule{
+		{
+			Field: "masterSn",
+			OP:    "eq",
+			Data:  card.SN,
+		},
+	})
+}
+
+//LinkSlave to multiroom master card at panaccess.
+//Both cards are fetched again so the checks use the current panaccess state.
+func (card *Smartcard) LinkSlave(pan *Panaccess, slave *Smartcard) error {
+	//Verify Fields
+	if slave == nil || slave.SN == "" || slave.SN == card.SN {
+		return errors.New("Please fill all required fields")
+	}
+	master, err := card.getBySN(pan, card.SN)
+	if err != nil {
+		return err
+	}
+	current, err := card.getBySN(pan, slave.SN)
+	if err != nil {
+		return err
+	}
+	if master.MasterSN != "" {
+		return fmt.Errorf("Smartcard %s is a slave of %s", master.SN, master.MasterSN)
+	}
+	if current.MasterSN == master.SN {
+		slave.MasterSN = master.SN
+		return nil
+	}
+	if current.MasterSN != "" {
+		return fmt.Errorf("Smartcard %s is already a slave of %s", current.SN, current.MasterSN)
+	}
+	if current.SubscriberCode != master.SubscriberCode {
+		return errors.New("Multiroom smartcards must belong to the same subscriber")
+	}
+	slaves, err := current.GetSlaves(pan)
+	if err != nil {
+		return err
+	}
+	if len(slaves) > 0 {
+		return fmt.Errorf("Smartcard %s is the master of %d smartcards", current.SN, len(slaves))
+	}
+	//Params
+	params := url.Values{}
+	params.Add("smartcardId", slave.SN)
+	params.Add("masterSn", master.SN)
+	//Call Function
+	resp, err := pan.Call(
+		"setMasterOfSmartcard",
+		&params,
+	)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.ErrorMessage)
+	}
+	slave.MasterSN = master.SN
+	return nil
+}
+
+//UnlinkFromMaster slave card at panaccess
+func (card *Smartcard) UnlinkFromMaster(pan *Panaccess) error {
+	//Not a slave
+	if card.MasterSN == "" {
+		return nil
+	}
+	err := card.action(pan, "removeMasterOfSmartcard")
+	if err != nil {
+		return err
+	}
+	card.MasterSN = ""
+	return nil
+}
+
+//ValidateMultiroom checks every slave has the same products as the master card,
+//the master is fetched again so the comparison doesn't rely on the receiver state
+func (card *Smartcard) ValidateMultiroom(pan *Panaccess) ([]MultiroomIssue, error) {
+	master, err := card.getBySN(pan, card.SN)
+	if err != nil {
+		return nil, err
+	}
+	slaves, err := master.GetSlaves(pan)
+	if err != nil {
+		return nil, err
+	}
+	products := map[string]bool{}
+	for _, p := range master.Products {
+		products[p] = true
+	}
+	issues := []MultiroomIssue{}
+	for _, slave := range slaves {
+		issue := MultiroomIssue{SN: slave.SN}
+		has := map[string]bool{}
+		for _, p := range slave.Products {
+			has[p] = true
+			if !products[p] {
+				issue.Extra = append(issue.Extra, p)
+			}
+		}
+		for _, p := range master.Products {
+			if !has[p] {
+				issue.Missing = append(issue.Missing, p)
+			}
+		}
+		if len(issue.Missing) > 0 || len(issue.Extra) > 0 {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+//getBySN smartcard from panaccess
+func (card *Smartcard) getBySN(pan *Panaccess, sn string) (*Smartcard, error) {
+	cards, err := card.GetWithFilter(pan, &url.Values{}, "AND", []Rule{
+		{
+			Field: "sn",
+			OP:    "eq",
+			Data:  sn,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(cards) == 0 {
+		return nil, &NotFoundError{Entity: "Smartcard", Key: sn}
+	}
+	return &cards[0], nil
+}
@@ -0,0 +1,156 @@
+package panaccess
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestSmartcardFlags(t *testing.T) {
+	mock, pan := newMockServer(t, Smartcard{SN: "100"})
+	card := Smartcard{SN: "100"}
+	tests := []struct {
+		name        string
+		action      func(*Smartcard, *Panaccess) error
+		blacklisted bool
+		defect      bool
+	}{
+		{"Blacklist", (*Smartcard).Blacklist, true, false},
+		{"MarkDefect", (*Smartcard).MarkDefect, true, true},
+		{"Unblacklist", (*Smartcard).Unblacklist, false, true},
+		{"UnmarkDefect", (*Smartcard).UnmarkDefect, false, false},
+	}
+	for _, test := range tests {
+		err := test.action(&card, pan)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		server := mock.smartcard("100")
+		if server.Blacklisted != test.blacklisted || server.Defect != test.defect {
+			t.Errorf("%s: server blacklisted=%v defect=%v, want %v %v", test.name, server.Blacklisted, server.Defect, test.blacklisted, test.defect)
+		}
+		if card.Blacklisted != test.blacklisted || card.Defect != test.defect {
+			t.Errorf("%s: local blacklisted=%v defect=%v, want %v %v", test.name, card.Blacklisted, card.Defect, test.blacklisted, test.defect)
+		}
+	}
+}
+
+func TestSmartcardFlagsUnknownCard(t *testing.T) {
+	_, pan := newMockServer(t)
+	card := Smartcard{SN: "404"}
+	err := card.Blacklist(pan)
+	if err == nil {
+		t.Fatal("Blacklist of unknown smartcard didn't fail")
+	}
+	if card.Blacklisted {
+		t.Error("Failed Blacklist changed local state")
+	}
+}
+
+func TestValidPIN(t *testing.T) {
+	tests := []struct {
+		pin  string
+		want bool
+	}{
+		{"0000", true},
+		{"1234", true},
+		{"", false},
+		{"123", false},
+		{"12345", false},
+		{"12a4", false},
+		{" 123", false},
+		{"１２３４", false},
+	}
+	for _, test := range tests {
+		if got := validPIN(test.pin); got != test.want {
+			t.Errorf("validPIN(%q) = %v, want %v", test.pin, got, test.want)
+		}
+	}
+}
+
+func TestSmartcardGetAllWithFilter(t *testing.T) {
+	cards := []Smartcard{}
+	for i := 0; i < 7; i++ {
+		cards = append(cards, Smartcard{SN: fmt.Sprint(100 + i), RegionID: i % 2})
+	}
+	mock, pan := newMockServer(t, cards...)
+	params := url.Values{}
+	params.Set("limit", "2")
+	card := Smartcard{}
+	got, err := card.GetAllWithFilter(pan, &params, "AND", []Rule{{Field: "regionId", OP: "eq", Data: "0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"100", "102", "104", "106"}
+	if len(got) != len(want) {
+		t.Fatalf("GetAllWithFilter returned %d cards, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].SN != want[i] {
+			t.Errorf("card %d = %s, want %s", i, got[i].SN, want[i])
+		}
+	}
+	pages := 0
+	for _, call := range mock.calls {
+		if call == "getListOfSmartcards" {
+			pages++
+		}
+	}
+	if pages != 2 {
+		t.Errorf("GetAllWithFilter made %d calls, want 2", pages)
+	}
+}
+
+func TestLinkSlave(t *testing.T) {
+	mock, pan := newMockServer(t,
+		Smartcard{SN: "M1", SubscriberCode: "A"},
+		Smartcard{SN: "M2", SubscriberCode: "A"},
+		Smartcard{SN: "S1", SubscriberCode: "A"},
+		Smartcard{SN: "S2", SubscriberCode: "A", MasterSN: "M2"},
+		Smartcard{SN: "S3", SubscriberCode: "B"},
+	)
+	master := Smartcard{SN: "M1"}
+	tests := []struct {
+		slave string
+		ok    bool
+	}{
+		{"S1", true},
+		{"S1", true},  //already linked to M1
+		{"S2", false}, //slave of M2
+		{"M2", false}, //master of S2
+		{"S3", false}, //another subscriber
+	}
+	for _, test := range tests {
+		slave := Smartcard{SN: test.slave}
+		err := master.LinkSlave(pan, &slave)
+		if (err == nil) != test.ok {
+			t.Errorf("LinkSlave(%s) error = %v, want ok %v", test.slave, err, test.ok)
+		}
+	}
+	if got := mock.smartcard("S1").MasterSN; got != "M1" {
+		t.Errorf("S1 master = %q, want M1", got)
+	}
+	if got := mock.smartcard("S2").MasterSN; got != "M2" {
+		t.Errorf("S2 master = %q, want M2", got)
+	}
+}
+
+func TestValidateMultiroomRefetchesMaster(t *testing.T) {
+	_, pan := newMockServer(t,
+		Smartcard{SN: "M", Products: []string{"Basic", "Sports"}},
+		Smartcard{SN: "S1", MasterSN: "M", Products: []string{"Basic", "Sports"}},
+		Smartcard{SN: "S2", MasterSN: "M", Products: []string{"Basic", "Movies"}},
+	)
+	//Zero-value receiver, only the SN is known
+	master := Smartcard{SN: "M"}
+	issues, err := master.ValidateMultiroom(pan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].SN != "S2" {
+		t.Fatalf("ValidateMultiroom = %v, want only S2", issues)
+	}
+	if fmt.Sprint(issues[0].Missing) != "[Sports]" || fmt.Sprint(issues[0].Extra) != "[Movies]" {
+		t.Errorf("S2 missing %v extra %v, want [Sports] [Movies]", issues[0].Missing, issues[0].Extra)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
+	"strings"
+	"time"
 )
 
 //GetListOfSubscribersResponse from panaccess
@@ -13,6 +16,13 @@ type GetListOfSubscribersResponse struct {
 	SubscriberEntries []Subscriber `json:"extendedSubscriberEntries"`
 }
 
+//SuspendRecord what Subscriber.Suspend changed, used to Resume
+type SuspendRecord struct {
+	SubscriberCode string
+	Orders         []Order
+	Smartcards     []Smartcard
+}
+
 //Subscriber class representation from panaccess
 type Subscriber struct {
 	SubscriberCode string      `json:"subscriberCode"`
@@ -98,6 +108,9 @@ func (sub *Subscriber) GetWithFilters(pan *Panaccess, params *url.Values, groupO
 	if err != nil {
 		return nil, err
 	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
 	return rows.SubscriberEntries, nil
 }
 
@@ -138,12 +151,43 @@ func (sub *Subscriber) GetOrders(pan *Panaccess, params *url.Values) ([]Order, e
 	if err != nil {
 		return nil, err
 	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+	var ordersResponse []Order
+	bodyBytes, err := json.Marshal(resp.Answer)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bodyBytes, &ordersResponse)
+	if err != nil {
+		return nil, err
+	}
+	return ordersResponse, nil
+}
+
+//GetOrderHistory of Subscriber, including terminated and expired orders
+func (sub *Subscriber) GetOrderHistory(pan *Panaccess, params *url.Values) ([]Order, error) {
+	if (*params).Get("limit") == "" {
+		(*params).Set("limit", "1000")
+	}
+	(*params).Set("subscriberCode", sub.SubscriberCode)
+	//Call Function
+	resp, err := pan.Call(
+		"getOrderHistoryOfSubscriber",
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
 	var ordersResponse []Order
-	bodyBytes, err := json.MarshalIndent(resp.Answer, "", "  ")
+	bodyBytes, err := json.Marshal(resp.Answer)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println(string(bodyBytes))
 	err = json.Unmarshal(bodyBytes, &ordersResponse)
 	if err != nil {
 		return nil, err
@@ -151,8 +195,40 @@ func (sub *Subscriber) GetOrders(pan *Panaccess, params *url.Values) ([]Order, e
 	return ordersResponse, nil
 }
 
+//GetOrderTimeline of Subscriber, current and historical order events sorted by time
+func (sub *Subscriber) GetOrderTimeline(pan *Panaccess) ([]OrderEvent, error) {
+	current, err := sub.GetOrders(pan, &url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	history, err := sub.GetOrderHistory(pan, &url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	//Current orders are fresher than their history entry
+	seen := map[int]bool{}
+	events := []OrderEvent{}
+	for _, order := range append(current, history...) {
+		if seen[order.ID] {
+			continue
+		}
+		seen[order.ID] = true
+		events = append(events, order.Events()...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events, nil
+}
+
 //LockOrder from subscriber at panaccess
 func (sub *Subscriber) LockOrder(pan *Panaccess, order *Order) error {
+	return sub.LockOrderUntil(pan, order, time.Time{})
+}
+
+//LockOrderUntil from subscriber at panaccess, panaccess enables it again at until.
+//A zero until locks the order without end date.
+func (sub *Subscriber) LockOrderUntil(pan *Panaccess, order *Order, until time.Time) error {
 	//Verify Fields
 	if order == nil {
 		return errors.New("Please fill all required fields")
@@ -160,6 +236,9 @@ func (sub *Subscriber) LockOrder(pan *Panaccess, order *Order) error {
 	params := url.Values{}
 	params.Add("orderId", fmt.Sprint(order.ID))
 	params.Add("subscriberCode", sub.SubscriberCode)
+	if !until.IsZero() {
+		params.Add("until", until.Format(timeLayout))
+	}
 	//Send data to make new subscriber
 	resp, err := pan.Call(
 		"disableOrderOfSubscriber",
@@ -175,6 +254,12 @@ func (sub *Subscriber) LockOrder(pan *Panaccess, order *Order) error {
 
 //UnlockOrder from subscriber at panaccess
 func (sub *Subscriber) UnlockOrder(pan *Panaccess, order *Order) error {
+	return sub.UnlockOrderUntil(pan, order, time.Time{})
+}
+
+//UnlockOrderUntil from subscriber at panaccess, panaccess disables it again at until.
+//A zero until unlocks the order without end date.
+func (sub *Subscriber) UnlockOrderUntil(pan *Panaccess, order *Order, until time.Time) error {
 	loggedIn, _ := pan.Loggedin()
 	if !loggedIn {
 		err := pan.Login()
@@ -189,7 +274,11 @@ func (sub *Subscriber) UnlockOrder(pan *Panaccess, order *Order) error {
 	params := url.Values{}
 	params.Add("orderId", fmt.Sprint(order.ID))
 	params.Add("subscriberCode", sub.SubscriberCode)
-	params.Add("until", "")
+	if until.IsZero() {
+		params.Add("until", "")
+	} else {
+		params.Add("until", until.Format(timeLayout))
+	}
 	//Send data to make new subscriber
 	resp, err := pan.Call(
 		"enableOrderOfSubscriber",
@@ -202,3 +291,212 @@ func (sub *Subscriber) UnlockOrder(pan *Panaccess, order *Order) error {
 	}
 	return nil
 }
+
+//GetSubscriberByCode returns the subscriber with exactly that code
+func (sub *Subscriber) GetSubscriberByCode(pan *Panaccess, code string) (*Subscriber, error) {
+	subs, err := sub.GetWithFilters(pan, &url.Values{}, "AND", []Rule{
+		{
+			Field: "subscriberCode",
+			OP:    "eq",
+			Data:  code,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		if subs[i].SubscriberCode == code {
+			return &subs[i], nil
+		}
+	}
+	return nil, &NotFoundError{Entity: "Subscriber", Key: code}
+}
+
+//FindSubscriberBySmartcard returns the subscriber owning the smartcard SN,
+//a Subscriber NotFoundError means no subscriber has that card
+func (sub *Subscriber) FindSubscriberBySmartcard(pan *Panaccess, sn string) (*Subscriber, error) {
+	subs, err := sub.GetWithFilters(pan, &url.Values{}, "AND", []Rule{
+		{
+			Field: "smartcards",
+			OP:    "cn",
+			Data:  sn,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	//Filter is a "contains", so keep only the exact SN
+	for i := range subs {
+		for _, card := range subs[i].Smartcards {
+			if card == sn {
+				return &subs[i], nil
+			}
+		}
+	}
+	return nil, &NotFoundError{Entity: "Subscriber", Key: sn}
+}
+
+//SearchSubscribers by code, name or smartcard containing query
+func (sub *Subscriber) SearchSubscribers(pan *Panaccess, query string) ([]Subscriber, error) {
+	rules := []Rule{}
+	for _, field := range []string{"subscriberCode", "firstName", "lastName", "smartcards"} {
+		rules = append(rules, Rule{
+			Field: field,
+			OP:    "cn",
+			Data:  query,
+		})
+	}
+	subs, err := sub.GetWithFilters(pan, &url.Values{}, "OR", rules)
+	if err != nil {
+		return nil, err
+	}
+	if len(subs) == 0 {
+		return nil, &NotFoundError{Entity: "Subscriber", Key: query}
+	}
+	return subs, nil
+}
+
+//AssignFreeSmartcard takes the first unused smartcard and assigns it to subscriber
+func (sub *Subscriber) AssignFreeSmartcard(pan *Panaccess) (*Smartcard, error) {
+	cards := Smartcard{}
+	params := url.Values{}
+	params.Add("limit", "1")
+	unused, err := cards.GetUnused(pan, &params)
+	if err != nil {
+		return nil, err
+	}
+	if len(unused) == 0 {
+		return nil, errors.New("No unused smartcards available")
+	}
+	card := unused[0]
+	err = card.AssignToSubscriber(pan, sub)
+	if err != nil {
+		return nil, err
+	}
+	sub.Smartcards = append(sub.Smartcards, card.SN)
+	return &card, nil
+}
+
+//RenewOrders renews every order of subscriber by calendar years, months and days.
+//Disabled orders (e.g. suspended for non-payment), terminated and unlimited ones are skipped.
+//Returns the renewed orders, failed ones are reported together in the error.
+func (sub *Subscriber) RenewOrders(pan *Panaccess, years, months, days int) ([]Order, error) {
+	orders, err := sub.GetOrders(pan, &url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	renewed := []Order{}
+	failed := []string{}
+	for _, order := range orders {
+		if order.Disabled || order.Terminated || order.Unlimited() {
+			continue
+		}
+		if order.SubscriberCode == "" {
+			order.SubscriberCode = sub.SubscriberCode
+		}
+		err = order.Renew(pan, years, months, days)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("order %d: %v", order.ID, err))
+			continue
+		}
+		renewed = append(renewed, order)
+	}
+	if len(failed) > 0 {
+		return renewed, errors.New(strings.Join(failed, "; "))
+	}
+	return renewed, nil
+}
+
+//Suspend disables every enabled order and smartcard of subscriber.
+//If something fails the changes already done are reverted.
+func (sub *Subscriber) Suspend(pan *Panaccess) (*SuspendRecord, error) {
+	record := &SuspendRecord{SubscriberCode: sub.SubscriberCode}
+	orders, err := sub.GetOrders(pan, &url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	cards, err := sub.GetSmartcards(pan)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range orders {
+		if order.Disabled {
+			continue
+		}
+		err = sub.LockOrder(pan, &order)
+		if err != nil {
+			return nil, sub.rollbackSuspend(pan, record, err)
+		}
+		record.Orders = append(record.Orders, order)
+	}
+	for _, card := range cards {
+		if card.Disabled {
+			continue
+		}
+		err = card.Lock(pan)
+		if err != nil {
+			return nil, sub.rollbackSuspend(pan, record, err)
+		}
+		record.Smartcards = append(record.Smartcards, card)
+	}
+	return record, nil
+}
+
+//rollbackSuspend undoes a partial Suspend and returns the original error
+func (sub *Subscriber) rollbackSuspend(pan *Panaccess, record *SuspendRecord, cause error) error {
+	err := sub.Resume(pan, record)
+	if err != nil {
+		return fmt.Errorf("%v (rollback failed: %v)", cause, err)
+	}
+	return cause
+}
+
+//Resume enables again exactly the orders and smartcards disabled by Suspend
+func (sub *Subscriber) Resume(pan *Panaccess, record *SuspendRecord) error {
+	if record == nil || record.SubscriberCode != sub.SubscriberCode {
+		return errors.New("Suspend record doesn't belong to subscriber")
+	}
+	failed := []string{}
+	for i := len(record.Smartcards) - 1; i >= 0; i-- {
+		err := record.Smartcards[i].Unlock(pan)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("smartcard %s: %v", record.Smartcards[i].SN, err))
+		}
+	}
+	for i := len(record.Orders) - 1; i >= 0; i-- {
+		err := sub.UnlockOrder(pan, &record.Orders[i])
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("order %d: %v", record.Orders[i].ID, err))
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+//commandOnSmartcards runs command on every smartcard of subscriber
+func (sub *Subscriber) commandOnSmartcards(pan *Panaccess, command func(*Smartcard, *Panaccess) error) ([]CommandResult, error) {
+	cards, err := sub.GetSmartcards(pan)
+	if err != nil {
+		return nil, err
+	}
+	results := []CommandResult{}
+	for i := range cards {
+		results = append(results, CommandResult{
+			SN:    cards[i].SN,
+			Error: command(&cards[i], pan),
+		})
+	}
+	return results, nil
+}
+
+//ResendEntitlements to every smartcard of subscriber
+func (sub *Subscriber) ResendEntitlements(pan *Panaccess) ([]CommandResult, error) {
+	return sub.commandOnSmartcards(pan, (*Smartcard).ResendEntitlements)
+}
+
+//ResetSmartcards resets every smartcard of subscriber
+func (sub *Subscriber) ResetSmartcards(pan *Panaccess) ([]CommandResult, error) {
+	return sub.commandOnSmartcards(pan, (*Smartcard).Reset)
+}